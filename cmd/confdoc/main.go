@@ -0,0 +1,69 @@
+// Command confdoc generates the JSON Schema and the Markdown reference of the
+// standard configuration file from the option structs used with conf.Parse.
+//
+// Usage:
+//
+//	confdoc -format schema -o configs/config.schema.json
+//	confdoc -format markdown -prefix GO_KIT -o docs/config.md
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yanking/gomicro/pkg/client/bootstrap"
+	"github.com/yanking/gomicro/pkg/conf"
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+// Config is the documented configuration: the client and logger sections of configs/config.yaml.
+type Config struct {
+	bootstrap.Config `mapstructure:",squash"`
+	Logger           logger.Options `mapstructure:"logger" desc:"Logger"`
+}
+
+func main() {
+	format := flag.String("format", "markdown", "output format: schema or markdown")
+	prefix := flag.String("prefix", "GO_KIT", "environment variable prefix")
+	output := flag.String("o", "", "output file, default stdout")
+	flag.Parse()
+
+	data, err := generate(*format, *prefix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "confdoc:", err)
+		os.Exit(2)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "confdoc:", err)
+		os.Exit(1)
+	}
+}
+
+func generate(format, prefix string) ([]byte, error) {
+	switch format {
+	case "schema":
+		data, err := conf.JSONSchema(&Config{}, "gomicro configuration")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "markdown":
+		var buf bytes.Buffer
+		buf.WriteString("# Configuration Reference\n\n")
+		buf.WriteString("<!-- Code generated by cmd/confdoc; DO NOT EDIT. -->\n\n")
+		fmt.Fprintf(&buf, "Keys of `configs/config.yaml`. Environment variables use the `%s` prefix; "+
+			"keys inside lists (`[]`) can only be set in files.\n\n", prefix)
+		buf.Write(conf.Markdown(conf.Describe(&Config{}, prefix)))
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, want schema or markdown", format)
+	}
+}
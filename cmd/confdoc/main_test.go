@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedDocsUpToDate(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+	}{
+		{format: "schema", file: "../../configs/config.schema.json"},
+		{format: "markdown", file: "../../docs/config.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			want, err := generate(tt.format, "GO_KIT")
+			if err != nil {
+				t.Fatalf("generate() error = %v", err)
+			}
+			got, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s is out of date, run go run ./cmd/confdoc -format %s -o %s", tt.file, tt.format, strings.TrimPrefix(tt.file, "../../"))
+			}
+		})
+	}
+
+	if _, err := generate("html", "GO_KIT"); err == nil {
+		t.Fatal("generate() error = nil for an unknown format")
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+
+	"github.com/yanking/gomicro/pkg/app"
 )
 
 func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
 	// 创建示例配置
 	config := &ExampleConfig{
 		ServerPort:  8080,
@@ -13,14 +18,34 @@ func main() {
 	}
 
 	// 创建服务上下文
-	ctx := &ExampleServiceContext{
-		Logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
-		Config: config,
+	ctx, err := NewServiceContext(logger, config)
+	if err != nil {
+		logger.Error("Failed to create service context", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// 创建应用，资源的清理函数会自动注册到应用的关闭流程中
+	application, err := app.New(ctx, "example", "v1.0.0")
+	if err != nil {
+		logger.Error("Failed to create application", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// 第一次获取时构造数据库连接
+	conn, err := app.Resource[*ExampleConnection](context.Background(), ctx.Resources(), "db")
+	if err != nil {
+		logger.Error("Failed to get database connection", slog.Any("error", err))
+		os.Exit(1)
 	}
 
 	// 输出示例信息
-	ctx.GetLogger().Info("Example service context created",
-		"server_port", ctx.GetConfig().(*ExampleConfig).GetServerPort(),
-		"database_dsn", ctx.GetConfig().(*ExampleConfig).GetDatabaseDSN(),
+	ctx.Logger().Info("Example service context created",
+		"server_port", ctx.Config().ServerPort,
+		"database_dsn", conn.DSN,
 	)
+
+	if err := application.Run(); err != nil {
+		logger.Error("Application exited with error", slog.Any("error", err))
+		os.Exit(1)
+	}
 }
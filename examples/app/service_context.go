@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/yanking/gomicro/pkg/app"
@@ -12,28 +13,26 @@ type ExampleConfig struct {
 	DatabaseDSN string
 }
 
-// GetServerPort 获取服务端口
-func (c *ExampleConfig) GetServerPort() int {
-	return c.ServerPort
+// ExampleConnection 示例数据库连接
+type ExampleConnection struct {
+	DSN string
 }
 
-// GetDatabaseDSN 获取数据库连接字符串
-func (c *ExampleConfig) GetDatabaseDSN() string {
-	return c.DatabaseDSN
+// Close 关闭连接
+func (c *ExampleConnection) Close(ctx context.Context) error {
+	return nil
 }
 
-// ExampleServiceContext 示例服务上下文
-type ExampleServiceContext struct {
-	Logger *slog.Logger
-	Config *ExampleConfig
-}
-
-// GetLogger 获取日志记录器
-func (ctx *ExampleServiceContext) GetLogger() *slog.Logger {
-	return ctx.Logger
-}
-
-// GetConfig 获取配置提供者
-func (ctx *ExampleServiceContext) GetConfig() app.IConfigProvider {
-	return ctx.Config
+// NewServiceContext 创建示例服务上下文，并注册延迟构造的数据库连接
+func NewServiceContext(logger *slog.Logger, config *ExampleConfig) (*app.ServiceContext[ExampleConfig], error) {
+	sc := app.NewServiceContext(logger, config)
+
+	err := app.Provide(sc.Resources(), "db", func(ctx context.Context) (*ExampleConnection, app.Close, error) {
+		conn := &ExampleConnection{DSN: sc.Config().DatabaseDSN}
+		return conn, conn.Close, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sc, nil
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/yanking/gomicro/pkg/app"
+	"github.com/yanking/gomicro/pkg/client/bootstrap"
+	"github.com/yanking/gomicro/pkg/client/database"
+	"github.com/yanking/gomicro/pkg/conf"
+)
+
+// Config 服务配置，客户端配置段直接嵌入 bootstrap.Config
+type Config struct {
+	bootstrap.Config `mapstructure:",squash"`
+}
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	var cfg Config
+	if err := conf.Parse("../../configs/config.yaml", &cfg); err != nil {
+		logger.Error("Failed to parse configs", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	sc := app.NewServiceContext(logger, &cfg)
+	application, err := app.New(sc, "bootstrap-example", "v1.0.0")
+	if err != nil {
+		logger.Error("Failed to create application", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// 根据配置创建所有客户端实例，关闭函数会注册到应用的关闭流程中
+	report, err := bootstrap.Init(context.Background(), application, &cfg.Config, bootstrap.WithLogger(logger))
+	if err != nil {
+		logger.Error("Failed to initialize clients", slog.Any("error", err))
+		os.Exit(1)
+	}
+	logger.Info("MySQL instances", slog.Any("instances", report.MySQL))
+
+	db := database.GetMySQL("default")
+	logger.Info("Default MySQL instance", slog.Bool("ready", db != nil))
+
+	if err := application.Run(); err != nil {
+		logger.Error("Application exited with error", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
@@ -44,13 +44,23 @@ func main() {
 	log := logger.Get()
 
 	// Create service context
-	serviceContext := appctx.NewServiceContext(log, cfg)
+	serviceContext, err := appctx.NewServiceContext(log, cfg)
+	if err != nil {
+		log.Error("failed to create service context", "error", err)
+		os.Exit(1)
+	}
 
 	// Create server component
-	orderServer := server.New(cfg)
+	orderServer, err := server.New(serviceContext)
+	if err != nil {
+		log.Error("failed to create server", "error", err)
+		os.Exit(1)
+	}
 
 	// Create application with components
-	application, err := gomicroapp.New(serviceContext, "OrderService", "v1.0.0", orderServer)
+	application, err := gomicroapp.New(serviceContext, "OrderService", "v1.0.0",
+		gomicroapp.WithComponents(orderServer),
+	)
 	if err != nil {
 		log.Error("failed to create application", "error", err)
 		os.Exit(1)
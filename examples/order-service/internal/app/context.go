@@ -2,52 +2,60 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/yanking/gomicro/examples/order-service/internal/config"
+	"github.com/yanking/gomicro/examples/order-service/internal/repository"
 	"github.com/yanking/gomicro/pkg/app"
 )
 
-// ConfigProvider implements the configuration provider interface.
-type ConfigProvider struct {
-	config *config.Config
-}
-
-// NewConfigProvider creates a new configuration provider.
-func NewConfigProvider(config *config.Config) *ConfigProvider {
-	return &ConfigProvider{
-		config: config,
-	}
-}
-
-// GetConfig returns the configuration.
-func (c *ConfigProvider) GetConfig() *config.Config {
-	return c.config
-}
+// OrderRepositoryResource is the name of the order repository resource.
+const OrderRepositoryResource = "order-repository"
 
-// Ensure ConfigProvider implements app.IConfigProvider
-var _ app.IConfigProvider = (*ConfigProvider)(nil)
+// ServiceContext is the typed service context of the order service.
+type ServiceContext = app.ServiceContext[config.Config]
 
-// ServiceContext implements the service context interface.
-type ServiceContext struct {
-	logger *slog.Logger
-	config *config.Config
-}
+// NewServiceContext creates a new service context and provides the shared resources.
+func NewServiceContext(logger *slog.Logger, cfg *config.Config) (*ServiceContext, error) {
+	sc := app.NewServiceContext(logger, cfg)
 
-// NewServiceContext creates a new service context.
-func NewServiceContext(logger *slog.Logger, config *config.Config) *ServiceContext {
-	return &ServiceContext{
-		logger: logger,
-		config: config,
+	err := app.Provide(sc.Resources(), OrderRepositoryResource,
+		func(ctx context.Context) (repository.OrderRepository, app.Close, error) {
+			return newOrderRepository(sc.Logger(), sc.Config()), nil, nil
+		})
+	if err != nil {
+		return nil, err
 	}
+	return sc, nil
 }
 
-// GetLogger returns the logger.
-func (s *ServiceContext) GetLogger() *slog.Logger {
-	return s.logger
-}
+// newOrderRepository creates the order repository based on the database configuration.
+func newOrderRepository(log *slog.Logger, cfg *config.Config) repository.OrderRepository {
+	// For now, we're using the in-memory repository
+	// In a production environment, you would initialize
+	// the appropriate repository based on configuration
+	defaultDBConfig := cfg.GetDefaultDatabaseConfig()
+	if defaultDBConfig == nil {
+		// Fallback to in-memory repository if no database config is found
+		log.Info("Using in-memory repository (fallback)")
+		return repository.NewInMemoryOrderRepository()
+	}
 
-// GetConfig returns the configuration provider.
-func (s *ServiceContext) GetConfig() app.IConfigProvider {
-	return NewConfigProvider(s.config)
+	switch defaultDBConfig.Driver {
+	case "mysql":
+		// Initialize MySQL repository
+		// repo = mysql.NewMySQLRepository(db)
+		// For demonstration purposes, we're still using in-memory
+		log.Info("Using MySQL repository")
+	case "mongo":
+		// Initialize MongoDB repository
+		// repo = mongo.NewMongoRepository(collection)
+		// For demonstration purposes, we're still using in-memory
+		log.Info("Using MongoDB repository")
+	default:
+		// Default to in-memory repository
+		log.Info("Using in-memory repository (default)")
+	}
+	return repository.NewInMemoryOrderRepository()
 }
@@ -12,12 +12,12 @@ import (
 type Config struct {
 	Server   ServerConfig     `mapstructure:"server"`
 	Log      LogConfig        `mapstructure:"log"`
-	Database []DatabaseConfig `mapstructure:"database"`
+	Database []DatabaseConfig `mapstructure:"database" validate:"dive"`
 }
 
 // ServerConfig holds the server configuration.
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
+	Port string `mapstructure:"port" validate:"required,numeric"`
 	Host string `mapstructure:"host"`
 }
 
@@ -32,15 +32,15 @@ type LogConfig struct {
 
 // DatabaseConfig holds the database configuration.
 type DatabaseConfig struct {
-	Instance string `mapstructure:"instance"`
-	Driver   string `mapstructure:"driver"`
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-	Name     string `mapstructure:"name"`
-	URI      string `mapstructure:"uri"`
-	Database string `mapstructure:"database"`
+	Instance string      `mapstructure:"instance" validate:"required"`
+	Driver   string      `mapstructure:"driver" validate:"required,oneof=memory mysql mongo"`
+	Host     string      `mapstructure:"host"`
+	Port     string      `mapstructure:"port"`
+	Username string      `mapstructure:"username"`
+	Password conf.Secret `mapstructure:"password"`
+	Name     string      `mapstructure:"name"`
+	URI      string      `mapstructure:"uri"`
+	Database string      `mapstructure:"database"`
 }
 
 // Load loads the configuration from the environment or config file.
@@ -5,12 +5,13 @@ import (
 	"context"
 	"log/slog"
 
+	appctx "github.com/yanking/gomicro/examples/order-service/internal/app"
 	"github.com/yanking/gomicro/examples/order-service/internal/config"
 	"github.com/yanking/gomicro/examples/order-service/internal/handler"
 	"github.com/yanking/gomicro/examples/order-service/internal/repository"
 	"github.com/yanking/gomicro/examples/order-service/internal/service"
+	"github.com/yanking/gomicro/pkg/app"
 	"github.com/yanking/gomicro/pkg/lifecycle"
-	"github.com/yanking/gomicro/pkg/logger"
 	"github.com/yanking/gomicro/pkg/transport/rest"
 )
 
@@ -25,41 +26,14 @@ type Server struct {
 }
 
 // New creates a new Server instance.
-func New(cfg *config.Config) *Server {
-	// Get logger instance
-	log := logger.Get()
+func New(sc *appctx.ServiceContext) (*Server, error) {
+	cfg := sc.Config()
+	log := sc.Logger()
 
 	// Initialize components
-	// For now, we're using the in-memory repository
-	// In a production environment, you would initialize
-	// the appropriate repository based on configuration
-	var repo repository.OrderRepository
-
-	// Get the default database configuration
-	defaultDBConfig := cfg.GetDefaultDatabaseConfig()
-	if defaultDBConfig != nil {
-		switch defaultDBConfig.Driver {
-		case "mysql":
-			// Initialize MySQL repository
-			// repo = mysql.NewMySQLRepository(db)
-			// For demonstration purposes, we're still using in-memory
-			repo = repository.NewInMemoryOrderRepository()
-			log.Info("Using MySQL repository")
-		case "mongo":
-			// Initialize MongoDB repository
-			// repo = mongo.NewMongoRepository(collection)
-			// For demonstration purposes, we're still using in-memory
-			repo = repository.NewInMemoryOrderRepository()
-			log.Info("Using MongoDB repository")
-		default:
-			// Default to in-memory repository
-			repo = repository.NewInMemoryOrderRepository()
-			log.Info("Using in-memory repository (default)")
-		}
-	} else {
-		// Fallback to in-memory repository if no database config is found
-		repo = repository.NewInMemoryOrderRepository()
-		log.Info("Using in-memory repository (fallback)")
+	repo, err := app.Resource[repository.OrderRepository](context.Background(), sc.Resources(), appctx.OrderRepositoryResource)
+	if err != nil {
+		return nil, err
 	}
 
 	orderService := service.NewOrderService(repo)
@@ -79,7 +53,7 @@ func New(cfg *config.Config) *Server {
 		handler:    orderHandler,
 		config:     cfg,
 		logger:     log,
-	}
+	}, nil
 }
 
 // registerRoutes registers the HTTP routes.
@@ -109,5 +83,13 @@ func (s *Server) Name() string {
 	return "order-service-server"
 }
 
-// Ensure Server implements lifecycle.Component interface
-var _ lifecycle.Component = (*Server)(nil)
+// LongRunning reports that Start blocks until the server is stopped.
+func (s *Server) LongRunning() bool {
+	return true
+}
+
+// Ensure Server implements lifecycle.Component and lifecycle.LongRunning interfaces
+var (
+	_ lifecycle.Component   = (*Server)(nil)
+	_ lifecycle.LongRunning = (*Server)(nil)
+)
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"github.com/yanking/gomicro/pkg/transport/admin"
+)
+
+// newAdminServer 创建由应用管理的管理服务器，自动提供构建信息、健康检查、当前配置和组件状态
+func (a *App) newAdminServer(config func() any) *admin.Server {
+	opts := []admin.Option{
+		admin.WithAddr(a.adminAddr),
+		admin.WithBuildInfo(a.appName, a.serviceVersion),
+		admin.WithHealthRegistry(a.health),
+		admin.WithConfig(config),
+		admin.WithComponents(a.componentStatuses),
+	}
+	return admin.NewServer(a.logger, append(opts, a.adminOpts...)...)
+}
+
+// componentStatuses 返回所有组件的运行状态
+func (a *App) componentStatuses() []admin.ComponentStatus {
+	statuses := a.Status()
+	result := make([]admin.ComponentStatus, 0, len(statuses))
+	for _, status := range statuses {
+		s := admin.ComponentStatus{
+			Name:      status.Name,
+			State:     status.State.String(),
+			Since:     status.Since,
+			Restarts:  status.Restarts,
+			DependsOn: status.DependsOn,
+		}
+		if status.Err != nil {
+			s.Error = status.Err.Error()
+		}
+		result = append(result, s)
+	}
+	return result
+}
@@ -5,30 +5,36 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/yanking/gomicro/pkg/health"
 	"github.com/yanking/gomicro/pkg/lifecycle"
+	"github.com/yanking/gomicro/pkg/logger"
+	"github.com/yanking/gomicro/pkg/transport/admin"
 )
 
-const shutdownOverallTimeout = 30 * time.Second
-
-// IConfigProvider 定义配置提供者的接口
-type IConfigProvider interface {
-	// 这里定义需要访问的配置方法，根据实际需要定义
-	// 例如：
-	// GetServerPort() int
-	// GetDatabaseDSN() string
-}
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	// loggerCloseOrder 是异步日志清理函数的执行顺序，在所有清理函数之后执行，
+	// 使关闭流程中记录的日志都能被写出
+	loggerCloseOrder = math.MaxInt
+	// restartDelay 和 maxRestartDelay 是 FailRestart 策略下重新启动组件前指数退避的初始和最大等待时间
+	restartDelay    = time.Second
+	maxRestartDelay = 30 * time.Second
+	// defaultStartupWindow 是未实现 lifecycle.Readiness 的长期运行组件在 Start 被调用后，
+	// 被视为就绪前需要持续运行的时间
+	defaultStartupWindow = 200 * time.Millisecond
+)
 
-// IServiceContext 定义服务上下文接口
-type IServiceContext interface {
-	GetLogger() *slog.Logger
-	GetConfig() IConfigProvider
-}
+// errUnexpectedExit 表示长期运行的组件在应用关闭前退出
+var errUnexpectedExit = errors.New("component exited unexpectedly")
 
 // Close 定义应用关闭时需要执行的清理函数类型
 type Close func(ctx context.Context) error
@@ -38,20 +44,100 @@ type App struct {
 	appName        string
 	serviceVersion string
 	logger         *slog.Logger
-	cfg            IConfigProvider
 	components     []lifecycle.Component
-	extCloses      []Close
-	runWg          sync.WaitGroup
+	// nodes 是按依赖关系拓扑排序后的组件
+	nodes      []*node
+	nodeByName map[string]*node
+	// allNodes 是所有组件以及 Supervisor 的子组件，子组件紧跟在所属的 Supervisor 之后
+	allNodes []*node
+	runWg    sync.WaitGroup
+	health   *health.Registry
+	// startupWindow 是未实现 lifecycle.Readiness 的长期运行组件被视为就绪前的启动观察时间
+	startupWindow time.Duration
+
+	// 管理服务器配置，adminAddr 为空时不启动管理服务器
+	adminAddr string
+	adminOpts []admin.Option
+
+	// 组件运行状态
+	stateMu      sync.Mutex
+	stateChanged chan struct{}
+	hooks        hooks
+
+	// 关闭流程配置
+	shutdownTimeout    time.Duration
+	preShutdownDelay   time.Duration
+	stopTimeouts       map[string]time.Duration
+	defaultStopTimeout time.Duration
+	closeMu            sync.Mutex
+	extCloses          []*closer
+	report             *ShutdownReport
+
+	// 配置重新加载
+	configFile string
+	// reloadConfig 重新读取配置并更新服务上下文，返回新的配置
+	reloadConfig func() (any, error)
+	reloadMu     sync.Mutex
+
+	// cancelRun 用于在组件发生致命错误时中断应用运行
+	cancelRun context.CancelCauseFunc
+	errMu     sync.Mutex
+	errs      []error
 }
 
 // New 创建一个新的应用实例
-func New(ctx IServiceContext, appName, version string, components ...lifecycle.Component) (*App, error) {
+// 组件会按照 lifecycle.Dependent 声明的依赖关系排序，存在未知依赖或循环依赖时返回错误。
+// 服务上下文中已构造和之后构造的资源的清理函数都会自动注册到应用的关闭流程中，
+// 异步日志处理器的关闭函数 logger.Close 也会自动注册，在所有清理函数之后写出缓冲的日志
+func New[C any](sc *ServiceContext[C], appName, version string, opts ...Option) (*App, error) {
 	app := &App{
-		appName:        appName,
-		serviceVersion: version,
-		logger:         ctx.GetLogger().With(slog.String("component", "app")),
-		cfg:            ctx.GetConfig(),
-		components:     components,
+		appName:         appName,
+		serviceVersion:  version,
+		logger:          sc.Logger().With(slog.String("component", "app")),
+		shutdownTimeout: defaultShutdownTimeout,
+		startupWindow:   defaultStartupWindow,
+		stopTimeouts:    make(map[string]time.Duration),
+		stateChanged:    make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	if app.health == nil {
+		app.health = health.NewRegistry()
+	}
+
+	if app.adminAddr != "" {
+		app.components = append(app.components, app.newAdminServer(func() any { return sc.Config() }))
+	}
+
+	nodes, err := sortComponents(app.components)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve component dependencies: %w", err)
+	}
+	app.nodes = nodes
+	app.nodeByName = make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		app.nodeByName[n.name()] = n
+		app.allNodes = append(app.allNodes, n)
+		// Supervisor 子组件的状态和重启次数由应用统一记录，参与状态查询、健康检查和回调
+		if sup, ok := n.component.(*Supervisor); ok {
+			app.allNodes = append(app.allNodes, sup.reportTo(app, n.policy)...)
+		}
+	}
+	app.health.Register(health.NewChecker(componentsCheckName, app.checkComponents), health.WithCacheTTL(0))
+	app.reloadConfig = func() (any, error) {
+		return reloadServiceConfig(sc, app.configFile)
+	}
+	sc.Resources().bind(app)
+	app.RegisterCloseWithOptions(logger.Close, WithCloseName("logger"), WithCloseOrder(loggerCloseOrder))
+
+	for name := range app.stopTimeouts {
+		if _, ok := app.nodeByName[name]; !ok {
+			app.logger.Warn("Stop timeout configured for unknown component", slog.String("component", name))
+		}
 	}
 
 	app.logger.Info("Creating new application instance...",
@@ -61,97 +147,214 @@ func New(ctx IServiceContext, appName, version string, components ...lifecycle.C
 	return app, nil
 }
 
-// RegisterClose 注册应用关闭时需要执行的清理函数
-func (a *App) RegisterClose(closer ...Close) {
-	a.extCloses = append(a.extCloses, closer...)
-}
-
 // Run 启动并运行应用
+// 任何采用 FailFatal 策略的组件启动失败或意外退出时，都会触发优雅关闭，
+// 并返回汇总了所有组件错误的 error
 func (a *App) Run() error {
 	a.logger.Info("Starting application...")
 
 	// 创建可被信号中断的上下文
-	appCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// 创建可被组件致命错误中断的上下文
+	appCtx, cancel := context.WithCancelCause(sigCtx)
+	defer cancel(nil)
+	a.cancelRun = cancel
+
+	// 监听重新加载信号
+	go a.watchReload(appCtx)
+
 	// 启动所有组件
 	a.startComponents(appCtx)
 
-	a.logger.Info("All components started, application is running.")
+	if appCtx.Err() == nil {
+		a.logger.Info("All components started, application is running.")
+	}
 
-	// 等待中断信号
+	// 等待中断信号或致命错误
 	<-appCtx.Done()
 
 	// 处理关闭流程
-	return a.shutdown()
+	if err := a.shutdown(); err != nil {
+		return err
+	}
+
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+	return errors.Join(a.errs...)
+}
+
+// fail 记录组件的致命错误并中断应用运行
+func (a *App) fail(err error) {
+	a.errMu.Lock()
+	a.errs = append(a.errs, err)
+	a.errMu.Unlock()
+
+	a.logger.Error("Fatal component failure, stopping application.", slog.Any("error", err))
+	a.cancelRun(err)
 }
 
-// startComponents 启动所有组件
+// startComponents 按依赖顺序启动所有组件，并等待所有组件就绪
 func (a *App) startComponents(ctx context.Context) {
-	for _, component := range a.components {
+	for _, n := range a.nodes {
 		a.runWg.Add(1)
-		go func(component lifecycle.Component) {
-			defer a.runWg.Done()
-			if err := component.Start(ctx); err != nil {
-				a.logger.Error("Component failed to start",
-					slog.String("component", component.Name()),
-					slog.Any("error", err),
-				)
-			}
-		}(component)
+		go a.runComponent(ctx, n)
+	}
+
+	for _, n := range a.nodes {
+		select {
+		case <-n.ready:
+		case <-n.done:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
-// shutdown 优雅关闭应用
-func (a *App) shutdown() error {
-	a.logger.Info("Shutdown signal received, stopping application.")
+// runComponent 等待依赖组件就绪后启动组件
+func (a *App) runComponent(ctx context.Context, n *node) {
+	defer a.runWg.Done()
+	defer n.markDone()
 
-	// 创建用于关闭流程的上下文
-	stopCtx, cancel := context.WithTimeout(context.Background(), shutdownOverallTimeout)
-	defer cancel()
+	if !a.waitDependencies(ctx, n) {
+		return
+	}
 
-	// 按相反顺序停止组件
-	a.stopComponents(stopCtx)
+	backoff := restartBackoff{
+		initial:     restartDelay,
+		max:         maxRestartDelay,
+		maxRestarts: math.MaxInt,
+		window:      defaultRestartWindow,
+	}
+	for {
+		// 应用已开始关闭时不再启动组件
+		if ctx.Err() != nil || !a.transition(n, []State{StatePending, StateFailed}, StateStarting, nil) {
+			return
+		}
+		a.logger.Info("Starting component...", slog.String("component", n.name()))
 
-	// 执行外部资源清理
-	a.closeExternalResources(stopCtx)
+		attemptDone := make(chan struct{})
+		a.watchReadiness(ctx, n, attemptDone)
+		err := n.component.Start(ctx)
+		close(attemptDone)
+		if ctx.Err() != nil {
+			// 应用正在关闭，Start 返回属于正常流程
+			return
+		}
 
-	// 等待所有组件启动goroutine完成
-	a.logger.Info("Waiting for component start goroutines to complete...")
-	a.runWg.Wait()
+		if err = exitError(n.component, err); err == nil {
+			// 未实现 lifecycle.Readiness 的一次性组件在 Start 成功返回后才视为就绪
+			if _, ok := n.component.(lifecycle.Readiness); !ok {
+				a.markRunning(n)
+			}
+			return
+		}
 
-	a.logger.Info("Application stopped gracefully.")
-	return nil
-}
+		a.setState(n, StateFailed, err)
+		a.logger.Error("Component failed",
+			slog.String("component", n.name()),
+			slog.String("policy", n.policy.String()),
+			slog.Any("error", err),
+		)
 
-// stopComponents 按相反顺序停止所有组件
-func (a *App) stopComponents(ctx context.Context) {
-	a.logger.Info("Starting graceful shutdown of components...")
+		switch n.policy {
+		case lifecycle.FailRestart:
+			delay, _ := backoff.next()
+			select {
+			case <-time.After(delay):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		case lifecycle.FailIgnore:
+			return
+		default:
+			a.fail(fmt.Errorf("component '%s' failed: %w", n.name(), err))
+			return
+		}
+	}
+}
 
-	for i := len(a.components) - 1; i >= 0; i-- {
-		component := a.components[i]
-		a.logger.Info("Stopping component...", slog.String("component", component.Name()))
+// watchReadiness 在组件就绪后将其切换到 StateRunning
+// 未实现 lifecycle.Readiness 的长期运行组件在 Start 被调用且持续运行 startupWindow 后视为就绪，
+// 一次性组件在 Start 成功返回后由 runComponent 标记就绪；attemptDone 在本次 Start 返回时关闭
+func (a *App) watchReadiness(ctx context.Context, n *node, attemptDone <-chan struct{}) {
+	r, ok := n.component.(lifecycle.Readiness)
+	if !ok && !isLongRunning(n.component) {
+		return
+	}
 
-		if err := component.Stop(ctx); err != nil {
-			a.logger.Error("Component failed to stop",
-				slog.String("component", component.Name()),
-				slog.Any("error", err),
-			)
+	go func() {
+		var ready <-chan struct{}
+		var startup <-chan time.Time
+		if ok {
+			ready = r.Ready()
 		} else {
-			a.logger.Info("Component stopped successfully",
-				slog.String("component", component.Name()),
-			)
+			timer := time.NewTimer(a.startupWindow)
+			defer timer.Stop()
+			startup = timer.C
 		}
+
+		select {
+		case <-ready:
+		case <-startup:
+		case <-attemptDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+		if a.markRunning(n) {
+			a.logger.Info("Component is ready", slog.String("component", n.name()))
+		}
+	}()
+}
+
+// markRunning 标记组件已就绪，并在组件处于 StateStarting 时将其切换到 StateRunning
+func (a *App) markRunning(n *node) bool {
+	n.markReady()
+	return a.transition(n, []State{StateStarting}, StateRunning, nil)
+}
+
+// exitError 判断组件的 Start 返回后是否属于异常退出
+func exitError(component lifecycle.Component, err error) error {
+	if err != nil {
+		return err
+	}
+	if isLongRunning(component) {
+		return errUnexpectedExit
 	}
+	return nil
 }
 
-// closeExternalResources 关闭外部资源
-func (a *App) closeExternalResources(ctx context.Context) {
-	a.logger.Info("Closing external resources...")
+// isLongRunning 判断组件的 Start 是否会阻塞直至组件被停止
+func isLongRunning(component lifecycle.Component) bool {
+	lr, ok := component.(lifecycle.LongRunning)
+	return ok && lr.LongRunning()
+}
 
-	for _, closeFn := range a.extCloses {
-		if err := closeFn(ctx); err != nil {
-			a.logger.Error("Failed to close resource", slog.Any("error", err))
+// waitDependencies 等待组件的所有依赖就绪
+// 如果某个依赖在就绪前退出或应用被中断，返回 false
+func (a *App) waitDependencies(ctx context.Context, n *node) bool {
+	for _, name := range n.deps {
+		dep := a.nodeByName[name]
+		select {
+		case <-dep.ready:
+		case <-dep.done:
+			select {
+			case <-dep.ready:
+				continue
+			default:
+			}
+			a.logger.Error("Component dependency exited before becoming ready, skip starting",
+				slog.String("component", n.name()),
+				slog.String("dependency", name),
+			)
+			a.setState(n, StateFailed, fmt.Errorf("dependency '%s' exited before becoming ready", name))
+			return false
+		case <-ctx.Done():
+			return false
 		}
 	}
+	return true
 }
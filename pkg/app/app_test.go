@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// testComponent 是测试用的组件，Start 调用 start，未设置 start 的长期运行组件阻塞直至被停止
+type testComponent struct {
+	name        string
+	deps        []string
+	longRunning bool
+	policy      lifecycle.FailurePolicy
+	start       func(ctx context.Context) error
+
+	mu      sync.Mutex
+	started time.Time
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func newTestComponent(name string, longRunning bool, start func(ctx context.Context) error, deps ...string) *testComponent {
+	return &testComponent{
+		name:        name,
+		deps:        deps,
+		longRunning: longRunning,
+		start:       start,
+		stopped:     make(chan struct{}),
+	}
+}
+
+func (c *testComponent) Start(ctx context.Context) error {
+	c.mu.Lock()
+	c.started = time.Now()
+	c.mu.Unlock()
+
+	if c.start != nil {
+		return c.start(ctx)
+	}
+	select {
+	case <-ctx.Done():
+	case <-c.stopped:
+	}
+	return nil
+}
+
+func (c *testComponent) Stop(context.Context) error {
+	c.once.Do(func() { close(c.stopped) })
+	return nil
+}
+
+func (c *testComponent) Name() string                           { return c.name }
+func (c *testComponent) DependsOn() []string                    { return c.deps }
+func (c *testComponent) LongRunning() bool                      { return c.longRunning }
+func (c *testComponent) FailurePolicy() lifecycle.FailurePolicy { return c.policy }
+
+func (c *testComponent) startedAt() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started
+}
+
+func newTestApp(t *testing.T, opts ...Option) *App {
+	t.Helper()
+	sc := NewServiceContext(slog.New(slog.NewTextHandler(io.Discard, nil)), &struct{}{})
+	a, err := New(sc, "test", "v0.0.0", append([]Option{WithShutdownTimeout(5 * time.Second)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return a
+}
+
+// runTestApp 运行应用并等待 Run 返回
+func runTestApp(t *testing.T, a *App) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- a.Run() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return")
+		return nil
+	}
+}
+
+func TestDependentNotStartedWhenDependencyFails(t *testing.T) {
+	errStart := errors.New("start failed")
+	tests := []struct {
+		name        string
+		longRunning bool
+		start       func(ctx context.Context) error
+	}{
+		{
+			name:        "long running fails immediately",
+			longRunning: true,
+			start:       func(context.Context) error { return errStart },
+		},
+		{
+			name:        "long running fails within startup window",
+			longRunning: true,
+			start: func(context.Context) error {
+				time.Sleep(20 * time.Millisecond)
+				return errStart
+			},
+		},
+		{
+			name:  "one-shot fails",
+			start: func(context.Context) error { return errStart },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := newTestComponent("db", tt.longRunning, tt.start)
+			dependent := newTestComponent("server", true, nil, "db")
+			a := newTestApp(t, WithComponents(dependent, dep))
+
+			err := runTestApp(t, a)
+			if !errors.Is(err, errStart) {
+				t.Fatalf("Run() error = %v, want %v", err, errStart)
+			}
+			if !dependent.startedAt().IsZero() {
+				t.Fatal("dependent started although its dependency failed")
+			}
+		})
+	}
+}
+
+func TestDependentStartsAfterDependencyIsReady(t *testing.T) {
+	errDone := errors.New("done")
+
+	var migrated time.Time
+	migrate := newTestComponent("migrate", false, func(context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		migrated = time.Now()
+		return nil
+	})
+	server := newTestComponent("server", true, nil, "migrate")
+	worker := newTestComponent("worker", true, func(context.Context) error { return errDone }, "server")
+	a := newTestApp(t, WithComponents(worker, server, migrate), WithStartupWindow(50*time.Millisecond))
+
+	if err := runTestApp(t, a); !errors.Is(err, errDone) {
+		t.Fatalf("Run() error = %v, want %v", err, errDone)
+	}
+
+	if server.startedAt().Before(migrated) {
+		t.Fatal("server started before the one-shot dependency returned")
+	}
+	if got := worker.startedAt().Sub(server.startedAt()); got < 50*time.Millisecond {
+		t.Fatalf("worker started %v after server, want at least the startup window", got)
+	}
+}
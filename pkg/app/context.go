@@ -0,0 +1,43 @@
+package app
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// ServiceContext 是应用的服务上下文，持有类型化的配置、日志记录器和具名资源注册表
+// C 是应用的配置类型，配置重新加载成功后 Config 返回新的配置实例
+type ServiceContext[C any] struct {
+	logger    *slog.Logger
+	config    atomic.Pointer[C]
+	resources *Resources
+}
+
+// NewServiceContext 创建一个新的服务上下文，logger 为 nil 时使用 slog.Default()
+func NewServiceContext[C any](logger *slog.Logger, cfg *C) *ServiceContext[C] {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	sc := &ServiceContext[C]{
+		logger:    logger,
+		resources: newResources(),
+	}
+	sc.config.Store(cfg)
+	return sc
+}
+
+// Logger 返回日志记录器
+func (s *ServiceContext[C]) Logger() *slog.Logger {
+	return s.logger
+}
+
+// Config 返回当前的配置
+func (s *ServiceContext[C]) Config() *C {
+	return s.config.Load()
+}
+
+// Resources 返回具名资源注册表
+func (s *ServiceContext[C]) Resources() *Resources {
+	return s.resources
+}
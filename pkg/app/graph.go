@@ -0,0 +1,159 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// node 表示依赖图中的一个组件
+type node struct {
+	component lifecycle.Component
+	deps      []string
+	policy    lifecycle.FailurePolicy
+
+	// ready 在组件就绪时关闭
+	ready     chan struct{}
+	readyOnce sync.Once
+	// done 在组件的 Start 返回（或组件因依赖失败而放弃启动）时关闭
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// 运行状态，由 App.stateMu 保护；独立运行的 Supervisor 的子组件由 Supervisor 的锁保护
+	state    State
+	since    time.Time
+	err      error
+	restarts int
+}
+
+func newNode(component lifecycle.Component) *node {
+	n := &node{
+		component: component,
+		ready:     make(chan struct{}),
+		done:      make(chan struct{}),
+		state:     StatePending,
+		since:     time.Now(),
+	}
+	if dep, ok := component.(lifecycle.Dependent); ok {
+		n.deps = dep.DependsOn()
+	}
+	if p, ok := component.(lifecycle.FailurePolicyProvider); ok {
+		n.policy = p.FailurePolicy()
+	}
+	return n
+}
+
+// name 返回组件名称
+func (n *node) name() string {
+	return n.component.Name()
+}
+
+// markReady 标记组件已就绪
+func (n *node) markReady() {
+	n.readyOnce.Do(func() { close(n.ready) })
+}
+
+// markDone 标记组件的 Start 已返回
+func (n *node) markDone() {
+	n.doneOnce.Do(func() { close(n.done) })
+}
+
+// sortComponents 按依赖关系对组件进行拓扑排序
+// 没有依赖关系的组件之间保持原有的注册顺序
+func sortComponents(components []lifecycle.Component) ([]*node, error) {
+	nodes := make(map[string]*node, len(components))
+	ordered := make([]*node, 0, len(components))
+	for _, component := range components {
+		n := newNode(component)
+		if _, exists := nodes[n.name()]; exists {
+			return nil, fmt.Errorf("duplicate component name '%s'", n.name())
+		}
+		nodes[n.name()] = n
+		ordered = append(ordered, n)
+	}
+
+	// 统计入度并校验依赖是否存在
+	inDegree := make(map[string]int, len(ordered))
+	dependents := make(map[string][]*node, len(ordered))
+	for _, n := range ordered {
+		for _, dep := range n.deps {
+			if _, exists := nodes[dep]; !exists {
+				return nil, fmt.Errorf("component '%s' depends on unknown component '%s'", n.name(), dep)
+			}
+			inDegree[n.name()]++
+			dependents[dep] = append(dependents[dep], n)
+		}
+	}
+
+	sorted := make([]*node, 0, len(ordered))
+	visited := make(map[string]bool, len(ordered))
+	for len(sorted) < len(ordered) {
+		progressed := false
+		for _, n := range ordered {
+			if visited[n.name()] || inDegree[n.name()] > 0 {
+				continue
+			}
+			visited[n.name()] = true
+			sorted = append(sorted, n)
+			for _, dependent := range dependents[n.name()] {
+				inDegree[dependent.name()]--
+			}
+			progressed = true
+		}
+		if !progressed {
+			return nil, fmt.Errorf("component dependency cycle detected: %s", findCycle(ordered, nodes, visited))
+		}
+	}
+
+	return sorted, nil
+}
+
+// findCycle 在未排序的组件中查找一个依赖环，返回形如 "a -> b -> a" 的描述
+func findCycle(ordered []*node, nodes map[string]*node, sorted map[string]bool) string {
+	const (
+		unvisited = iota
+		visiting
+		finished
+	)
+	state := make(map[string]int, len(ordered))
+	var path []string
+
+	var visit func(n *node) []string
+	visit = func(n *node) []string {
+		state[n.name()] = visiting
+		path = append(path, n.name())
+		for _, dep := range n.deps {
+			if sorted[dep] {
+				continue
+			}
+			switch state[dep] {
+			case visiting:
+				for i, name := range path {
+					if name == dep {
+						return append(append([]string{}, path[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(nodes[dep]); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n.name()] = finished
+		return nil
+	}
+
+	for _, n := range ordered {
+		if sorted[n.name()] || state[n.name()] != unvisited {
+			continue
+		}
+		if cycle := visit(n); cycle != nil {
+			return strings.Join(cycle, " -> ")
+		}
+	}
+	return "unknown"
+}
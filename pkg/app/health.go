@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// componentsCheckName 是 App 内置的组件就绪检查项名称
+const componentsCheckName = "components"
+
+// Health 返回应用的健康检查注册表
+func (a *App) Health() *health.Registry {
+	return a.health
+}
+
+// RegisterHealthChecker 向应用的健康检查注册表注册检查项
+func (a *App) RegisterHealthChecker(checker health.Checker, opts ...health.CheckOption) {
+	a.health.Register(checker, opts...)
+}
+
+// checkComponents 检查组件是否可以提供服务
+//   - 采用 FailIgnore 策略的组件不参与检查
+//   - 采用 FailRestart 策略的组件和 Supervisor 的子组件在重启期间视为降级，返回包装了 health.ErrDegraded 的错误
+//   - 长期运行的组件必须处于 StateRunning
+//   - 一次性组件不要求处于 StateRunning，只有失败时才视为未就绪
+func (a *App) checkComponents(_ context.Context) error {
+	var down, degraded []string
+	a.stateMu.Lock()
+	for _, n := range a.allNodes {
+		if n.policy == lifecycle.FailIgnore || n.state == StateRunning {
+			continue
+		}
+		problem := fmt.Sprintf("%s is %s", n.name(), n.state)
+		switch {
+		case n.policy == lifecycle.FailRestart && (n.state == StateFailed || n.state == StateStarting && n.restarts > 0):
+			degraded = append(degraded, problem)
+		case isLongRunning(n.component) || n.state == StateFailed:
+			down = append(down, problem)
+		}
+	}
+	a.stateMu.Unlock()
+
+	if len(down) > 0 {
+		return fmt.Errorf("components not running: %s", strings.Join(down, ", "))
+	}
+	if len(degraded) > 0 {
+		return fmt.Errorf("components restarting: %s: %w", strings.Join(degraded, ", "), health.ErrDegraded)
+	}
+	return nil
+}
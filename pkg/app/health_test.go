@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+func TestCheckComponents(t *testing.T) {
+	tests := []struct {
+		name        string
+		longRunning bool
+		policy      lifecycle.FailurePolicy
+		state       State
+		restarts    int
+		want        health.Status
+	}{
+		{name: "long running running", longRunning: true, state: StateRunning, want: health.StatusUp},
+		{name: "long running starting", longRunning: true, state: StateStarting, want: health.StatusDown},
+		{name: "long running stopped", longRunning: true, state: StateStopped, want: health.StatusDown},
+		{name: "one-shot pending", state: StatePending, want: health.StatusUp},
+		{name: "one-shot failed", state: StateFailed, want: health.StatusDown},
+		{name: "ignored failed", longRunning: true, policy: lifecycle.FailIgnore, state: StateFailed, want: health.StatusUp},
+		{name: "restart backoff", longRunning: true, policy: lifecycle.FailRestart, state: StateFailed, want: health.StatusDegraded},
+		{name: "restart starting", longRunning: true, policy: lifecycle.FailRestart, state: StateStarting, restarts: 1, want: health.StatusDegraded},
+		{name: "restart first start", longRunning: true, policy: lifecycle.FailRestart, state: StateStarting, want: health.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestComponent("c", tt.longRunning, nil)
+			c.policy = tt.policy
+			a := newTestApp(t, WithComponents(c))
+			n := a.nodeByName["c"]
+			n.state = tt.state
+			n.restarts = tt.restarts
+
+			report, err := a.Health().Check(context.Background(), componentsCheckName)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if report.Status != tt.want {
+				t.Fatalf("Status = %s, want %s (%v)", report.Status, tt.want, report.Checks)
+			}
+		})
+	}
+}
+
+func TestCheckComponentsDownWinsOverDegraded(t *testing.T) {
+	restarting := newTestComponent("restarting", true, nil)
+	restarting.policy = lifecycle.FailRestart
+	stopped := newTestComponent("stopped", true, nil)
+	a := newTestApp(t, WithComponents(restarting, stopped))
+	a.nodeByName["restarting"].state = StateFailed
+	a.nodeByName["stopped"].state = StateStopped
+
+	err := a.checkComponents(context.Background())
+	if err == nil || errors.Is(err, health.ErrDegraded) {
+		t.Fatalf("checkComponents() error = %v, want a non-degraded error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"time"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+	"github.com/yanking/gomicro/pkg/transport/admin"
+)
+
+// Option 定义应用选项函数
+type Option func(*App)
+
+// WithComponents 添加由应用管理生命周期的组件
+func WithComponents(components ...lifecycle.Component) Option {
+	return func(a *App) {
+		a.components = append(a.components, components...)
+	}
+}
+
+// WithShutdownTimeout 设置整个关闭流程（包括预关闭等待、停止组件和清理资源）的总超时时间，默认 30 秒
+// 通常应略小于 Kubernetes 的 terminationGracePeriodSeconds
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		a.shutdownTimeout = timeout
+	}
+}
+
+// WithPreShutdownDelay 设置预关闭等待时间
+// 收到关闭信号后，App 先将实现了 lifecycle.Drainer 的组件标记为不健康，
+// 等待该时间让负载均衡器摘除流量，然后再停止组件
+func WithPreShutdownDelay(delay time.Duration) Option {
+	return func(a *App) {
+		a.preShutdownDelay = delay
+	}
+}
+
+// WithStopTimeout 为指定名称的组件设置停止超时时间
+func WithStopTimeout(name string, timeout time.Duration) Option {
+	return func(a *App) {
+		a.stopTimeouts[name] = timeout
+	}
+}
+
+// WithDefaultStopTimeout 设置未单独配置的组件的停止超时时间，默认为 0，即只受总超时时间限制
+func WithDefaultStopTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		a.defaultStopTimeout = timeout
+	}
+}
+
+// WithStartupWindow 设置未实现 lifecycle.Readiness 的长期运行组件被视为就绪前的启动观察时间，默认 200 毫秒
+// Start 在该时间内返回错误的组件不会被视为就绪，依赖它的组件也不会被启动；该时间同样用于 Supervisor 的子组件
+func WithStartupWindow(window time.Duration) Option {
+	return func(a *App) {
+		a.startupWindow = window
+	}
+}
+
+// WithHealth 设置应用的健康检查注册表，默认创建一个新的注册表
+// 将同一个注册表传给 rest.WithHealthRegistry 和 rpc.WithHealthRegistry，
+// 即可让 HTTP 探针和 gRPC 健康检查服务共享应用注册的检查项
+func WithHealth(registry *health.Registry) Option {
+	return func(a *App) {
+		a.health = registry
+	}
+}
+
+// WithConfigFile 设置应用的配置文件路径
+// 收到 SIGHUP 或调用 App.Reload 时，App 使用 conf.Parse 将该文件解析到一个新的配置实例中，
+// 替换服务上下文中的配置，按配置中的 logger.Options 配置段更新日志级别，并推送给实现了 lifecycle.Reloadable 的组件
+func WithConfigFile(path string) Option {
+	return func(a *App) {
+		a.configFile = path
+	}
+}
+
+// WithAdmin 在指定地址上启动管理服务器（pprof、健康检查、构建信息、当前配置、组件状态等）
+// 管理服务器作为组件由应用管理，构建信息、健康检查注册表、配置和组件状态由应用自动提供，
+// 其余功能（例如运行时调整日志级别）通过 opts 启用
+// 管理端点没有鉴权，addr 通常使用本机回环地址，例如 "127.0.0.1:9090"，该端口不得对外暴露
+func WithAdmin(addr string, opts ...admin.Option) Option {
+	return func(a *App) {
+		a.adminAddr = addr
+		a.adminOpts = append(a.adminOpts, opts...)
+	}
+}
+
+// CloseOption 定义清理函数的注册选项
+type CloseOption func(*closer)
+
+// WithCloseName 设置清理函数的名称，用于日志和关闭报告
+func WithCloseName(name string) CloseOption {
+	return func(c *closer) {
+		c.name = name
+	}
+}
+
+// WithCloseTimeout 设置清理函数的超时时间，默认只受总超时时间限制
+func WithCloseTimeout(timeout time.Duration) CloseOption {
+	return func(c *closer) {
+		c.timeout = timeout
+	}
+}
+
+// WithCloseOrder 设置清理函数的执行顺序，数值越小越先执行，默认为 0；
+// 顺序相同的清理函数按注册顺序执行
+func WithCloseOrder(order int) CloseOption {
+	return func(c *closer) {
+		c.order = order
+	}
+}
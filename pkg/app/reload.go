@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/conf"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+// Reload 重新读取配置文件，并将新的配置推送给所有实现了 lifecycle.Reloadable 的组件
+// 未通过 WithConfigFile 设置配置文件时，组件收到的是当前的配置；重新加载成功后 ServiceContext.Config 返回新的配置。
+// 新的配置包含 logger.Options 类型的配置段时，先用它更新 logger.GetLevels() 的日志级别和模块级别。
+// 配置解析失败时不会调用任何组件；日志级别或单个组件应用失败不会影响其他组件，返回汇总的错误
+func (a *App) Reload(ctx context.Context) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	a.logger.Info("Reloading application configuration...", slog.String("config", a.configFile))
+	start := time.Now()
+
+	cfg, err := a.reloadConfig()
+	if err != nil {
+		a.logger.Error("Failed to reload configuration, keep running with the current one", slog.Any("error", err))
+		return err
+	}
+
+	var errs []error
+	if err := a.applyLogLevels(cfg); err != nil {
+		a.logger.Error("Failed to apply log levels", slog.Any("error", err))
+		errs = append(errs, fmt.Errorf("failed to apply log levels: %w", err))
+	}
+
+	for _, n := range a.nodes {
+		r, ok := n.component.(lifecycle.Reloadable)
+		if !ok {
+			continue
+		}
+
+		componentStart := time.Now()
+		if err := r.Reload(ctx, cfg); err != nil {
+			a.logger.Error("Component failed to reload",
+				slog.String("component", n.name()),
+				slog.Duration("elapsed", time.Since(componentStart)),
+				slog.Any("error", err),
+			)
+			errs = append(errs, fmt.Errorf("component '%s' failed to reload: %w", n.name(), err))
+			continue
+		}
+		a.logger.Info("Component reloaded successfully",
+			slog.String("component", n.name()),
+			slog.Duration("elapsed", time.Since(componentStart)),
+		)
+	}
+
+	if len(errs) > 0 {
+		a.logger.Warn("Configuration reloaded, some components failed to apply it",
+			slog.Int("failed", len(errs)),
+			slog.Duration("elapsed", time.Since(start)),
+		)
+		return errors.Join(errs...)
+	}
+
+	a.logger.Info("Configuration reloaded.", slog.Duration("elapsed", time.Since(start)))
+	return nil
+}
+
+// reloadServiceConfig 将配置文件解析到一个新的配置实例中，解析成功后替换服务上下文中的配置
+// configFile 为空时直接返回当前的配置
+func reloadServiceConfig[C any](sc *ServiceContext[C], configFile string) (any, error) {
+	if configFile == "" {
+		return sc.Config(), nil
+	}
+
+	cfg := new(C)
+	if err := conf.Parse(configFile, cfg); err != nil {
+		return nil, err
+	}
+	sc.config.Store(cfg)
+	return cfg, nil
+}
+
+// applyLogLevels 使用新配置中的 logger 配置段更新全局日志级别
+// 未设置配置文件时配置没有变化，不会覆盖运行时通过 /loglevel 调整的级别
+func (a *App) applyLogLevels(cfg any) error {
+	if a.configFile == "" {
+		return nil
+	}
+	opts := loggerOptions(reflect.ValueOf(cfg))
+	if opts == nil {
+		return nil
+	}
+	return logger.GetLevels().Apply(opts)
+}
+
+// loggerOptions 返回配置结构体中 logger.Options 类型的字段，包括嵌入的结构体中的字段，不存在时返回 nil
+func loggerOptions(v reflect.Value) *logger.Options {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	optionsType := reflect.TypeFor[logger.Options]()
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		switch value := v.Field(i); {
+		case field.Type == optionsType:
+			opts := value.Interface().(logger.Options)
+			return &opts
+		case field.Type == reflect.PointerTo(optionsType):
+			if !value.IsNil() {
+				return value.Interface().(*logger.Options)
+			}
+		case field.Anonymous:
+			if opts := loggerOptions(value); opts != nil {
+				return opts
+			}
+		}
+	}
+	return nil
+}
+
+// watchReload 在收到 SIGHUP 时重新加载配置，直到 ctx 被取消
+func (a *App) watchReload(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-sigCh:
+			a.logger.Info("Reload signal received.")
+			_ = a.Reload(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+type reloadTestConfig struct {
+	Logger logger.Options `mapstructure:"logger"`
+}
+
+func TestReloadAppliesLogLevels(t *testing.T) {
+	levels := logger.GetLevels()
+	level, modules := levels.Level(), levels.ModuleLevels()
+	t.Cleanup(func() {
+		levels.SetLevel(level)
+		for module := range levels.ModuleLevels() {
+			levels.ResetModuleLevel(module)
+		}
+		for module, level := range modules {
+			levels.SetModuleLevel(module, level)
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("logger:\n  level: info\n")
+
+	sc := NewServiceContext(slog.New(slog.NewTextHandler(io.Discard, nil)), &reloadTestConfig{})
+	a, err := New(sc, "test", "v0.0.0", WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	write("logger:\n  level: warn\n  modules:\n    mysql: debug\n")
+	if err := a.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := levels.Level(); got != slog.LevelWarn {
+		t.Fatalf("Level() = %v, want %v", got, slog.LevelWarn)
+	}
+	if got, ok := levels.ModuleLevel("mysql"); !ok || got != slog.LevelDebug {
+		t.Fatalf("ModuleLevel(mysql) = %v, %v, want %v, true", got, ok, slog.LevelDebug)
+	}
+
+	// 无效的级别不修改当前级别
+	write("logger:\n  level: loud\n")
+	if err := a.Reload(context.Background()); err == nil {
+		t.Fatal("Reload() error = nil, want an invalid level error")
+	}
+	if got := levels.Level(); got != slog.LevelWarn {
+		t.Fatalf("Level() = %v after an invalid reload, want %v", got, slog.LevelWarn)
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// resourceCloseOrder 是资源清理函数的基准执行顺序
+// 资源在用户注册的清理函数（默认顺序 0）之后按构造的逆序关闭，后构造的资源先关闭
+const resourceCloseOrder = 1 << 20
+
+// Resources 是具名资源（数据库连接、Redis 客户端、Kafka 生产者等）的注册表
+// 资源在第一次被获取时才会构造，构造时返回的清理函数会自动注册到应用的关闭流程中
+type Resources struct {
+	mu      sync.Mutex
+	entries map[string]*resource
+	seq     int
+	app     *App
+	// pending 是应用创建之前已构造的资源，在应用创建时注册其清理函数
+	pending []*resource
+}
+
+// resource 表示一个已注册的资源
+type resource struct {
+	name  string
+	build func(ctx context.Context) (any, Close, error)
+
+	// mu 保证资源只被构造一次，构造失败时下次获取会重试
+	mu    sync.Mutex
+	built bool
+	value any
+	close Close
+	seq   int
+}
+
+func newResources() *Resources {
+	return &Resources{
+		entries: make(map[string]*resource),
+	}
+}
+
+// Provide 注册一个延迟构造的资源，同名资源已存在时返回错误
+// build 返回资源实例和可选的清理函数（可以为 nil）
+func Provide[T any](r *Resources, name string, build func(ctx context.Context) (T, Close, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[name]; exists {
+		return fmt.Errorf("resource '%s' already provided", name)
+	}
+
+	r.entries[name] = &resource{
+		name: name,
+		build: func(ctx context.Context) (any, Close, error) {
+			return build(ctx)
+		},
+	}
+	return nil
+}
+
+// Resource 获取指定名称的资源，资源尚未构造时先构造
+// 资源未注册、构造失败或类型不匹配时返回错误
+func Resource[T any](ctx context.Context, r *Resources, name string) (T, error) {
+	var zero T
+
+	r.mu.Lock()
+	res, exists := r.entries[name]
+	r.mu.Unlock()
+	if !exists {
+		return zero, fmt.Errorf("resource '%s' not provided", name)
+	}
+
+	value, err := r.get(ctx, res)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("resource '%s' is %T, not %T", name, value, zero)
+	}
+	return typed, nil
+}
+
+// Names 返回所有已注册资源的名称
+func (r *Resources) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// get 返回资源实例，必要时构造资源并注册其清理函数
+func (r *Resources) get(ctx context.Context, res *resource) (any, error) {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	if res.built {
+		return res.value, nil
+	}
+
+	value, closeFn, err := res.build(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource '%s': %w", res.name, err)
+	}
+	res.value = value
+	res.close = closeFn
+	res.built = true
+
+	if closeFn != nil {
+		r.mu.Lock()
+		r.seq++
+		res.seq = r.seq
+		app := r.app
+		if app == nil {
+			r.pending = append(r.pending, res)
+		}
+		r.mu.Unlock()
+
+		if app != nil {
+			registerResourceClose(app, res)
+		}
+	}
+	return value, nil
+}
+
+// bind 将注册表关联到应用，并注册应用创建之前已构造的资源的清理函数
+func (r *Resources) bind(app *App) {
+	r.mu.Lock()
+	r.app = app
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	for _, res := range pending {
+		registerResourceClose(app, res)
+	}
+}
+
+// registerResourceClose 将资源的清理函数注册到应用的关闭流程中
+func registerResourceClose(app *App, res *resource) {
+	app.RegisterCloseWithOptions(res.close,
+		WithCloseName("resource:"+res.name),
+		WithCloseOrder(resourceCloseOrder-res.seq),
+	)
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// closer 表示一个已注册的清理函数
+type closer struct {
+	name    string
+	fn      Close
+	timeout time.Duration
+	order   int
+}
+
+// StepReport 记录关闭流程中单个组件或清理函数的执行情况
+type StepReport struct {
+	// Name 是组件或清理函数的名称
+	Name string
+	// Budget 是分配的超时时间，0 表示只受总超时时间限制
+	Budget time.Duration
+	// Elapsed 是实际耗时
+	Elapsed time.Duration
+	// Overran 表示是否超出了分配的超时时间
+	Overran bool
+	// Err 是执行返回的错误
+	Err error
+}
+
+// ShutdownReport 记录一次关闭流程的执行情况
+type ShutdownReport struct {
+	// Elapsed 是关闭流程的总耗时
+	Elapsed time.Duration
+	// Components 按停止顺序记录各组件的停止情况
+	Components []StepReport
+	// Closers 按执行顺序记录各清理函数的执行情况
+	Closers []StepReport
+}
+
+// Overran 返回所有超时的组件和清理函数的名称
+func (r *ShutdownReport) Overran() []string {
+	var names []string
+	for _, step := range r.Components {
+		if step.Overran {
+			names = append(names, step.Name)
+		}
+	}
+	for _, step := range r.Closers {
+		if step.Overran {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// RegisterClose 注册应用关闭时需要执行的清理函数，按注册顺序执行
+func (a *App) RegisterClose(closer ...Close) {
+	for _, closeFn := range closer {
+		a.RegisterCloseWithOptions(closeFn)
+	}
+}
+
+// RegisterCloseWithOptions 注册带名称、执行顺序和超时时间的清理函数
+func (a *App) RegisterCloseWithOptions(closeFn Close, opts ...CloseOption) {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+
+	c := &closer{
+		name: fmt.Sprintf("close-%d", len(a.extCloses)),
+		fn:   closeFn,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	a.extCloses = append(a.extCloses, c)
+}
+
+// ShutdownReport 返回最近一次关闭流程的执行报告，应用尚未关闭时返回 nil
+func (a *App) ShutdownReport() *ShutdownReport {
+	return a.report
+}
+
+// shutdown 优雅关闭应用
+// 关闭流程分为以下阶段：标记组件不健康、预关闭等待、停止组件、清理外部资源，全部阶段共享总超时时间
+func (a *App) shutdown() error {
+	a.logger.Info("Shutdown signal received, stopping application.")
+	start := time.Now()
+	report := &ShutdownReport{}
+
+	// 创建用于关闭流程的上下文
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	// 标记组件不健康并等待负载均衡器摘除流量
+	a.drainComponents(stopCtx)
+
+	// 按相反顺序停止组件
+	report.Components = a.stopComponents(stopCtx)
+
+	// 执行外部资源清理
+	report.Closers = a.closeExternalResources(stopCtx)
+
+	// 等待所有组件启动goroutine完成
+	a.logger.Info("Waiting for component start goroutines to complete...")
+	done := make(chan struct{})
+	go func() {
+		a.runWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-stopCtx.Done():
+		a.logger.Warn("Timed out waiting for component start goroutines to complete")
+	}
+
+	report.Elapsed = time.Since(start)
+	a.report = report
+
+	if overran := report.Overran(); len(overran) > 0 {
+		a.logger.Warn("Application stopped, some steps overran their shutdown budget",
+			slog.Any("overran", overran),
+			slog.Duration("elapsed", report.Elapsed),
+		)
+		return nil
+	}
+
+	a.logger.Info("Application stopped gracefully.", slog.Duration("elapsed", report.Elapsed))
+	return nil
+}
+
+// drainComponents 将组件标记为不健康，并在预关闭等待时间内等待负载均衡器摘除流量
+func (a *App) drainComponents(ctx context.Context) {
+	a.health.SetDraining(true)
+
+	for i := len(a.nodes) - 1; i >= 0; i-- {
+		if d, ok := a.nodes[i].component.(lifecycle.Drainer); ok {
+			a.logger.Info("Draining component...", slog.String("component", a.nodes[i].name()))
+			d.Drain()
+		}
+	}
+
+	if a.preShutdownDelay <= 0 {
+		return
+	}
+
+	a.logger.Info("Waiting before stopping components...", slog.Duration("delay", a.preShutdownDelay))
+	timer := time.NewTimer(a.preShutdownDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// stopComponents 按依赖关系的逆序停止所有组件，依赖方总是先于被依赖方停止
+func (a *App) stopComponents(ctx context.Context) []StepReport {
+	a.logger.Info("Starting graceful shutdown of components...")
+
+	reports := make([]StepReport, 0, len(a.nodes))
+	for i := len(a.nodes) - 1; i >= 0; i-- {
+		n := a.nodes[i]
+		component := n.component
+		a.logger.Info("Stopping component...", slog.String("component", component.Name()))
+		a.setState(n, StateStopping, nil)
+
+		budget, ok := a.stopTimeouts[component.Name()]
+		if !ok {
+			budget = a.defaultStopTimeout
+		}
+
+		step := runStep(ctx, component.Name(), budget, component.Stop)
+		reports = append(reports, step)
+		if step.Err != nil {
+			a.setState(n, StateFailed, step.Err)
+		} else {
+			a.setState(n, StateStopped, nil)
+		}
+
+		switch {
+		case step.Err != nil:
+			a.logger.Error("Component failed to stop",
+				slog.String("component", component.Name()),
+				slog.Duration("elapsed", step.Elapsed),
+				slog.Any("error", step.Err),
+			)
+		case step.Overran:
+			a.logger.Warn("Component stop overran its budget",
+				slog.String("component", component.Name()),
+				slog.Duration("budget", step.Budget),
+				slog.Duration("elapsed", step.Elapsed),
+			)
+		default:
+			a.logger.Info("Component stopped successfully",
+				slog.String("component", component.Name()),
+				slog.Duration("elapsed", step.Elapsed),
+			)
+		}
+	}
+	return reports
+}
+
+// closeExternalResources 按执行顺序关闭外部资源
+func (a *App) closeExternalResources(ctx context.Context) []StepReport {
+	a.logger.Info("Closing external resources...")
+
+	a.closeMu.Lock()
+	closers := make([]*closer, len(a.extCloses))
+	copy(closers, a.extCloses)
+	a.closeMu.Unlock()
+
+	sort.SliceStable(closers, func(i, j int) bool {
+		return closers[i].order < closers[j].order
+	})
+
+	reports := make([]StepReport, 0, len(closers))
+	for _, c := range closers {
+		step := runStep(ctx, c.name, c.timeout, c.fn)
+		reports = append(reports, step)
+
+		if step.Err != nil {
+			a.logger.Error("Failed to close resource",
+				slog.String("resource", c.name),
+				slog.Duration("elapsed", step.Elapsed),
+				slog.Any("error", step.Err),
+			)
+		} else if step.Overran {
+			a.logger.Warn("Resource close overran its budget",
+				slog.String("resource", c.name),
+				slog.Duration("budget", step.Budget),
+				slog.Duration("elapsed", step.Elapsed),
+			)
+		}
+	}
+	return reports
+}
+
+// runStep 在分配的超时时间内执行关闭步骤并记录其执行情况
+func runStep(ctx context.Context, name string, budget time.Duration, fn func(ctx context.Context) error) StepReport {
+	stepCtx := ctx
+	if budget > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := fn(stepCtx)
+	elapsed := time.Since(start)
+
+	return StepReport{
+		Name:    name,
+		Budget:  budget,
+		Elapsed: elapsed,
+		Overran: errors.Is(stepCtx.Err(), context.DeadlineExceeded) || (budget > 0 && elapsed > budget),
+		Err:     err,
+	}
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// State 表示组件的运行状态
+type State int
+
+const (
+	// StatePending 表示组件尚未启动，例如正在等待依赖的组件就绪
+	StatePending State = iota
+	// StateStarting 表示组件的 Start 已被调用，但组件尚未就绪
+	StateStarting
+	// StateRunning 表示组件已就绪
+	StateRunning
+	// StateStopping 表示组件的 Stop 正在执行
+	StateStopping
+	// StateStopped 表示组件已停止
+	StateStopped
+	// StateFailed 表示组件启动失败、意外退出或停止失败
+	StateFailed
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 将状态序列化为状态名称
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ComponentStatus 是组件运行状态的快照
+type ComponentStatus struct {
+	// Name 是组件名称
+	Name string
+	// State 是组件的当前状态
+	State State
+	// Since 是进入当前状态的时间
+	Since time.Time
+	// Err 是导致组件进入 StateFailed 的错误
+	Err error
+	// Restarts 是组件因 FailRestart 策略或被 Supervisor 重新启动的次数
+	Restarts int
+	// DependsOn 是组件依赖的组件名称
+	DependsOn []string
+}
+
+// Hook 是组件状态变化时被调用的函数
+type Hook func(status ComponentStatus)
+
+// hooks 保存已注册的组件状态变化回调
+type hooks struct {
+	started []Hook
+	stopped []Hook
+	failed  []Hook
+}
+
+// OnStarted 注册组件进入 StateRunning 时被调用的函数
+func (a *App) OnStarted(hook Hook) {
+	a.stateMu.Lock()
+	a.hooks.started = append(a.hooks.started, hook)
+	a.stateMu.Unlock()
+}
+
+// OnStopped 注册组件进入 StateStopped 时被调用的函数
+func (a *App) OnStopped(hook Hook) {
+	a.stateMu.Lock()
+	a.hooks.stopped = append(a.hooks.stopped, hook)
+	a.stateMu.Unlock()
+}
+
+// OnFailed 注册组件进入 StateFailed 时被调用的函数
+func (a *App) OnFailed(hook Hook) {
+	a.stateMu.Lock()
+	a.hooks.failed = append(a.hooks.failed, hook)
+	a.stateMu.Unlock()
+}
+
+// Status 按启动顺序返回所有组件的运行状态快照，Supervisor 的子组件紧跟在所属的 Supervisor 之后
+func (a *App) Status() []ComponentStatus {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	statuses := make([]ComponentStatus, 0, len(a.allNodes))
+	for _, n := range a.allNodes {
+		statuses = append(statuses, n.status())
+	}
+	return statuses
+}
+
+// WaitRunning 等待组件就绪，判断规则与组件健康检查一致
+//   - 采用 FailIgnore 策略的组件不参与等待
+//   - 采用 FailRestart 策略的组件在重启期间继续等待
+//   - 长期运行的组件必须进入 StateRunning
+//   - 一次性组件不要求进入 StateRunning，只有失败时才返回错误
+//
+// 任一参与等待的组件进入 StateStopping 或 StateStopped 时也返回错误，ctx 被取消时返回 ctx.Err()
+func (a *App) WaitRunning(ctx context.Context) error {
+	for {
+		a.stateMu.Lock()
+		changed := a.stateChanged
+		var err error
+		running := true
+		for _, n := range a.allNodes {
+			if n.policy == lifecycle.FailIgnore || n.state == StateRunning {
+				continue
+			}
+			switch {
+			case n.state == StateStopping || n.state == StateStopped:
+				err = fmt.Errorf("component '%s' is %s", n.name(), n.state)
+			case n.state == StateFailed && n.policy != lifecycle.FailRestart:
+				err = fmt.Errorf("component '%s' failed: %w", n.name(), n.err)
+			case n.state == StateFailed || isLongRunning(n.component):
+				running = false
+			}
+			if err != nil {
+				break
+			}
+		}
+		a.stateMu.Unlock()
+
+		if err != nil {
+			return err
+		}
+		if running {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// setState 将组件切换到指定状态，并调用对应的回调函数
+func (a *App) setState(n *node, state State, err error) {
+	a.transition(n, nil, state, err)
+}
+
+// transition 在组件处于 from 中的任一状态时将其切换到 to，from 为空时不限制当前状态
+// 返回是否发生了切换
+func (a *App) transition(n *node, from []State, to State, err error) bool {
+	a.stateMu.Lock()
+	if !n.transition(from, to, err) {
+		a.stateMu.Unlock()
+		return false
+	}
+	status := n.status()
+
+	var fns []Hook
+	switch to {
+	case StateRunning:
+		fns = a.hooks.started
+	case StateStopped:
+		fns = a.hooks.stopped
+	case StateFailed:
+		fns = a.hooks.failed
+	}
+
+	// 通知所有等待状态变化的调用方
+	close(a.stateChanged)
+	a.stateChanged = make(chan struct{})
+	a.stateMu.Unlock()
+
+	for _, fn := range fns {
+		a.runHook(fn, status)
+	}
+	return true
+}
+
+// runHook 调用回调函数，回调函数中的 panic 只会被记录日志
+func (a *App) runHook(fn Hook, status ComponentStatus) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.logger.Error("Component state hook panicked",
+				slog.String("component", status.Name),
+				slog.String("state", status.State.String()),
+				slog.Any("panic", r),
+			)
+		}
+	}()
+	fn(status)
+}
+
+// transition 在组件处于 from 中的任一状态时将其切换到 to，from 为空时不限制当前状态，返回是否发生了切换
+// 组件首次启动之后每次重新进入 StateStarting 都计为一次重启，
+// App 的 FailRestart 策略和 Supervisor 的重启使用同一个计数；调用方需持有保护组件状态的锁
+func (n *node) transition(from []State, to State, err error) bool {
+	if len(from) > 0 && !containsState(from, n.state) {
+		return false
+	}
+	if to == StateStarting && n.state != StatePending {
+		n.restarts++
+	}
+	n.state = to
+	n.err = err
+	n.since = time.Now()
+	return true
+}
+
+// status 返回组件的运行状态快照，调用方需持有保护组件状态的锁
+func (n *node) status() ComponentStatus {
+	return ComponentStatus{
+		Name:      n.name(),
+		State:     n.state,
+		Since:     n.since,
+		Err:       n.err,
+		Restarts:  n.restarts,
+		DependsOn: n.deps,
+	}
+}
+
+func containsState(states []State, state State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
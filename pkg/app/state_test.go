@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+func TestWaitRunning(t *testing.T) {
+	// 返回 context.DeadlineExceeded 表示 WaitRunning 仍在等待
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name        string
+		longRunning bool
+		policy      lifecycle.FailurePolicy
+		state       State
+		restarts    int
+		wantWait    bool
+		wantErr     bool
+	}{
+		{name: "long running running", longRunning: true, state: StateRunning},
+		{name: "long running starting", longRunning: true, state: StateStarting, wantWait: true},
+		{name: "long running failed", longRunning: true, state: StateFailed, wantErr: true},
+		{name: "long running stopped", longRunning: true, state: StateStopped, wantErr: true},
+		{name: "one-shot pending", state: StatePending},
+		{name: "one-shot failed", state: StateFailed, wantErr: true},
+		{name: "ignored failed", longRunning: true, policy: lifecycle.FailIgnore, state: StateFailed},
+		{name: "ignored stopped", longRunning: true, policy: lifecycle.FailIgnore, state: StateStopped},
+		{name: "restart backoff", longRunning: true, policy: lifecycle.FailRestart, state: StateFailed, wantWait: true},
+		{name: "restart starting", longRunning: true, policy: lifecycle.FailRestart, state: StateStarting, restarts: 1, wantWait: true},
+		{name: "one-shot restart backoff", policy: lifecycle.FailRestart, state: StateFailed, wantWait: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestComponent("c", tt.longRunning, nil)
+			c.policy = tt.policy
+			a := newTestApp(t, WithComponents(c))
+			n := a.nodeByName["c"]
+			n.state = tt.state
+			n.err = errFailed
+			n.restarts = tt.restarts
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := a.WaitRunning(ctx)
+
+			switch {
+			case tt.wantWait:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("WaitRunning() error = %v, want it to keep waiting", err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("WaitRunning() error = %v, want a component error", err)
+				}
+			case err != nil:
+				t.Fatalf("WaitRunning() error = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,518 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// Strategy 定义 Supervisor 在子组件失败时的重启策略
+type Strategy int
+
+const (
+	// OneForOne 只重启失败的子组件
+	OneForOne Strategy = iota
+	// OneForAll 任一子组件失败时，停止其余子组件并重启全部子组件
+	OneForAll
+)
+
+// String 返回策略名称
+func (s Strategy) String() string {
+	switch s {
+	case OneForOne:
+		return "one_for_one"
+	case OneForAll:
+		return "one_for_all"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	defaultMaxRestarts      = 5
+	defaultRestartWindow    = time.Minute
+	defaultInitialBackoff   = time.Second
+	defaultMaxBackoff       = 30 * time.Second
+	defaultChildStopTimeout = 10 * time.Second
+)
+
+// SupervisorOption 定义 Supervisor 选项函数
+type SupervisorOption func(*Supervisor)
+
+// WithStrategy 设置重启策略，默认为 OneForOne
+func WithStrategy(strategy Strategy) SupervisorOption {
+	return func(s *Supervisor) {
+		s.strategy = strategy
+	}
+}
+
+// WithMaxRestarts 设置重启强度：在 window 时间内最多重启 maxRestarts 次，
+// 超过后 Supervisor 停止所有子组件并从 Start 返回错误
+func WithMaxRestarts(maxRestarts int, window time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.backoff.maxRestarts = maxRestarts
+		s.backoff.window = window
+	}
+}
+
+// WithBackoff 设置指数退避的初始等待时间和最大等待时间
+func WithBackoff(initial, maxDelay time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.backoff.initial = initial
+		s.backoff.max = maxDelay
+	}
+}
+
+// WithChildStopTimeout 设置 OneForAll 策略下或超出重启强度时停止子组件的超时时间
+func WithChildStopTimeout(timeout time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.childStopTimeout = timeout
+	}
+}
+
+// Supervisor 是一个监管子组件并在其失败时按策略重启的组件
+// Supervisor 本身实现了 lifecycle.Component，可以像普通组件一样交给 App 管理，
+// 此时子组件的状态和重启次数由 App 记录，出现在 App.Status 和组件健康检查中。
+// 子组件的 Start 返回错误，或实现了 lifecycle.LongRunning 的子组件意外返回时视为失败；
+// 子组件需要支持在 Start 返回（或 Stop）之后再次调用 Start。
+type Supervisor struct {
+	name             string
+	logger           *slog.Logger
+	strategy         Strategy
+	backoff          restartBackoff
+	childStopTimeout time.Duration
+	// startupWindow 是未实现 lifecycle.Readiness 的长期运行子组件被视为就绪前的启动观察时间
+	startupWindow time.Duration
+
+	children []*child
+	exits    chan childExit
+
+	// stateMu 保护子组件的运行状态，transition 切换子组件的状态；
+	// Supervisor 由 App 管理时两者分别为 App 的锁和 App.transition
+	stateMu    *sync.Mutex
+	transition func(n *node, from []State, to State, err error) bool
+
+	// mu 保护子组件的运行状态
+	mu       sync.Mutex
+	started  atomic.Bool
+	stopping chan struct{}
+	stopOnce sync.Once
+	loopDone chan struct{}
+	ready    chan struct{}
+}
+
+// child 表示一个被监管的子组件，运行状态和重启次数记录在 node 中
+type child struct {
+	*node
+
+	// gen 标识子组件的当前运行，用于忽略已被主动停止的运行产生的退出事件
+	gen     int
+	running bool
+	cancel  context.CancelFunc
+	// done 在本次运行的 Start 返回时关闭，每次运行重新创建，不使用 node.done
+	done chan struct{}
+}
+
+// childExit 表示子组件的 Start 已返回
+type childExit struct {
+	child *child
+	gen   int
+	err   error
+}
+
+// NewSupervisor 创建一个新的 Supervisor 实例
+func NewSupervisor(logger *slog.Logger, name string, children []lifecycle.Component, opts ...SupervisorOption) *Supervisor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Supervisor{
+		name:     name,
+		logger:   logger.With(slog.String("component", "supervisor"), slog.String("supervisor", name)),
+		strategy: OneForOne,
+		backoff: restartBackoff{
+			initial:     defaultInitialBackoff,
+			max:         defaultMaxBackoff,
+			maxRestarts: defaultMaxRestarts,
+			window:      defaultRestartWindow,
+		},
+		childStopTimeout: defaultChildStopTimeout,
+		startupWindow:    defaultStartupWindow,
+		stateMu:          new(sync.Mutex),
+		children:         make([]*child, 0, len(children)),
+		exits:            make(chan childExit),
+		stopping:         make(chan struct{}),
+		loopDone:         make(chan struct{}),
+		ready:            make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.transition = s.transitionChild
+	for _, component := range children {
+		n := newNode(component)
+		// 子组件由 Supervisor 重启，忽略其自身声明的失败策略
+		n.policy = lifecycle.FailRestart
+		s.children = append(s.children, &child{node: n})
+	}
+
+	return s
+}
+
+// reportTo 使 Supervisor 通过 App 记录子组件的状态，返回子组件的状态节点
+// policy 是 Supervisor 自身的失败策略，采用 FailIgnore 策略时子组件同样不参与健康检查
+func (s *Supervisor) reportTo(a *App, policy lifecycle.FailurePolicy) []*node {
+	s.stateMu = &a.stateMu
+	s.transition = a.transition
+	s.startupWindow = a.startupWindow
+
+	nodes := make([]*node, 0, len(s.children))
+	for _, c := range s.children {
+		if policy == lifecycle.FailIgnore {
+			c.policy = lifecycle.FailIgnore
+		}
+		nodes = append(nodes, c.node)
+	}
+	return nodes
+}
+
+// transitionChild 切换独立运行的 Supervisor 的子组件的状态
+func (s *Supervisor) transitionChild(n *node, from []State, to State, err error) bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return n.transition(from, to, err)
+}
+
+// Start 启动所有子组件并监管它们，直到 ctx 被取消、Stop 被调用或超出重启强度
+func (s *Supervisor) Start(ctx context.Context) error {
+	if !s.started.CompareAndSwap(false, true) {
+		return errors.New("supervisor already started")
+	}
+	defer close(s.loopDone)
+
+	select {
+	case <-s.stopping:
+		return nil
+	default:
+	}
+
+	s.logger.Info("Starting supervisor",
+		slog.String("strategy", s.strategy.String()),
+		slog.Int("children", len(s.children)),
+	)
+
+	s.mu.Lock()
+	for _, c := range s.children {
+		s.startChild(ctx, c)
+	}
+	s.mu.Unlock()
+
+	go s.watchReadiness(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopping:
+			return nil
+		case exit := <-s.exits:
+			if ctx.Err() != nil || s.isStopping() {
+				return nil
+			}
+
+			s.mu.Lock()
+			stale := exit.gen != exit.child.gen
+			s.mu.Unlock()
+			if stale {
+				continue
+			}
+
+			name := exit.child.name()
+			err := exitError(exit.child.component, exit.err)
+			if err == nil {
+				// 一次性子组件在 Start 成功返回后视为就绪
+				s.transition(exit.child.node, []State{StateStarting}, StateRunning, nil)
+				s.logger.Info("Child completed", slog.String("child", name))
+				continue
+			}
+
+			s.transition(exit.child.node, nil, StateFailed, err)
+			s.logger.Error("Child failed", slog.String("child", name), slog.Any("error", err))
+
+			delay, ok := s.backoff.next()
+			if !ok {
+				s.logger.Error("Restart intensity exceeded, stopping all children",
+					slog.Int("max_restarts", s.backoff.maxRestarts),
+					slog.Duration("window", s.backoff.window),
+				)
+				s.stopChildren()
+				return fmt.Errorf("supervisor '%s': restart intensity exceeded (%d restarts in %s), last failure of '%s': %w",
+					s.name, s.backoff.maxRestarts, s.backoff.window, name, err)
+			}
+
+			s.logger.Warn("Restarting child",
+				slog.String("child", name),
+				slog.Duration("backoff", delay),
+			)
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-s.stopping:
+				timer.Stop()
+				return nil
+			}
+
+			s.restart(ctx, exit.child)
+		}
+	}
+}
+
+// Stop 停止监管并按相反顺序停止所有子组件
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+
+	if s.started.Load() {
+		select {
+		case <-s.loopDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	for i := len(s.children) - 1; i >= 0; i-- {
+		c := s.children[i]
+		if err := s.stopChild(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("child '%s': %w", c.name(), err))
+		}
+	}
+
+	s.logger.Info("Supervisor stopped")
+	return errors.Join(errs...)
+}
+
+// Name 返回组件名称
+func (s *Supervisor) Name() string {
+	return s.name
+}
+
+// LongRunning 声明 Start 会阻塞直至 Supervisor 停止
+func (s *Supervisor) LongRunning() bool {
+	return true
+}
+
+// Ready 返回一个在所有子组件首次就绪后被关闭的通道
+func (s *Supervisor) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// RestartCounts 返回每个子组件的重启次数，与 App.Status 中子组件的 Restarts 相同
+func (s *Supervisor) RestartCounts() map[string]int {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	counts := make(map[string]int, len(s.children))
+	for _, c := range s.children {
+		counts[c.name()] = c.restarts
+	}
+	return counts
+}
+
+// restart 按策略重启失败的子组件
+func (s *Supervisor) restart(ctx context.Context, failed *child) {
+	if s.strategy == OneForAll {
+		s.stopChildren()
+		s.mu.Lock()
+		for _, c := range s.children {
+			s.startChild(ctx, c)
+		}
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	s.startChild(ctx, failed)
+	s.mu.Unlock()
+}
+
+// startChild 在新的 goroutine 中运行子组件，调用方需持有 s.mu
+func (s *Supervisor) startChild(ctx context.Context, c *child) {
+	c.gen++
+	gen := c.gen
+	childCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.cancel = cancel
+	c.done = done
+	c.running = true
+
+	s.transition(c.node, nil, StateStarting, nil)
+	s.logger.Info("Starting child", slog.String("child", c.name()))
+
+	go s.watchChildReadiness(childCtx, c, done)
+	go func() {
+		err := c.component.Start(childCtx)
+		cancel()
+
+		s.mu.Lock()
+		if c.gen == gen {
+			c.running = false
+		}
+		s.mu.Unlock()
+		close(done)
+
+		select {
+		case s.exits <- childExit{child: c, gen: gen, err: err}:
+		case <-s.loopDone:
+		}
+	}()
+}
+
+// stopChild 停止正在运行的子组件，并等待其 Start 返回
+func (s *Supervisor) stopChild(ctx context.Context, c *child) error {
+	s.mu.Lock()
+	if !c.running {
+		s.mu.Unlock()
+		return nil
+	}
+	// 标记当前运行已失效，忽略其随后产生的退出事件
+	c.gen++
+	c.running = false
+	cancel, done := c.cancel, c.done
+	s.mu.Unlock()
+
+	s.transition(c.node, nil, StateStopping, nil)
+	s.logger.Info("Stopping child", slog.String("child", c.name()))
+	err := c.component.Stop(ctx)
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	if err != nil {
+		s.transition(c.node, nil, StateFailed, err)
+	} else {
+		s.transition(c.node, nil, StateStopped, nil)
+	}
+	return err
+}
+
+// stopChildren 在 childStopTimeout 内按相反顺序停止所有正在运行的子组件
+func (s *Supervisor) stopChildren() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.childStopTimeout)
+	defer cancel()
+
+	for i := len(s.children) - 1; i >= 0; i-- {
+		c := s.children[i]
+		if err := s.stopChild(ctx, c); err != nil {
+			s.logger.Error("Failed to stop child",
+				slog.String("child", c.name()),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// watchChildReadiness 在子组件就绪后将其切换到 StateRunning，就绪规则与 App 管理的组件相同：
+// 实现了 lifecycle.Readiness 的子组件在 Ready 后就绪，其他长期运行的子组件在 Start 被调用并持续运行
+// startupWindow 后就绪，一次性子组件在 Start 成功返回后就绪；done 在本次 Start 返回时关闭
+func (s *Supervisor) watchChildReadiness(ctx context.Context, c *child, done <-chan struct{}) {
+	var ready <-chan struct{}
+	var startup <-chan time.Time
+	if r, ok := c.component.(lifecycle.Readiness); ok {
+		ready = r.Ready()
+	} else if isLongRunning(c.component) {
+		timer := time.NewTimer(s.startupWindow)
+		defer timer.Stop()
+		startup = timer.C
+	} else {
+		return
+	}
+
+	select {
+	case <-ready:
+	case <-startup:
+	case <-done:
+		return
+	case <-ctx.Done():
+		return
+	}
+	s.transition(c.node, []State{StateStarting}, StateRunning, nil)
+}
+
+// restartBackoff 记录时间窗口内的重启并计算重启前的指数退避等待时间，
+// App 的 FailRestart 策略和 Supervisor 使用同一套重启规则
+type restartBackoff struct {
+	initial     time.Duration
+	max         time.Duration
+	maxRestarts int
+	window      time.Duration
+	times       []time.Time
+}
+
+// next 记录一次重启并返回重启前的等待时间，时间窗口内的重启次数超过上限时返回 false
+// 退避时间按时间窗口内的重启次数计算，组件稳定运行一个时间窗口后退避时间恢复为初始值
+func (b *restartBackoff) next() (time.Duration, bool) {
+	now := time.Now()
+	kept := b.times[:0]
+	for _, t := range b.times {
+		if now.Sub(t) < b.window {
+			kept = append(kept, t)
+		}
+	}
+	b.times = append(kept, now)
+	if len(b.times) > b.maxRestarts {
+		return 0, false
+	}
+	return backoffDelay(b.initial, b.max, len(b.times)-1), true
+}
+
+// watchReadiness 等待所有实现了 lifecycle.Readiness 的子组件就绪
+func (s *Supervisor) watchReadiness(ctx context.Context) {
+	for _, c := range s.children {
+		r, ok := c.component.(lifecycle.Readiness)
+		if !ok {
+			continue
+		}
+		select {
+		case <-r.Ready():
+		case <-ctx.Done():
+			return
+		case <-s.loopDone:
+			return
+		}
+	}
+	close(s.ready)
+	s.logger.Info("All children are ready")
+}
+
+func (s *Supervisor) isStopping() bool {
+	select {
+	case <-s.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoffDelay 计算第 attempt 次（从 0 开始）重试的指数退避等待时间
+func backoffDelay(initial, maxDelay time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+// supervisedChild 是测试用的子组件：fail 返回错误时 Start 立即以该错误返回，否则阻塞直至 ctx 被取消
+// 每次 Start 被调用时向 runs 发送本次运行的序号
+type supervisedChild struct {
+	name string
+	fail func(run int) error
+	runs chan int
+
+	mu    sync.Mutex
+	count int
+}
+
+func newSupervisedChild(name string, fail func(run int) error) *supervisedChild {
+	return &supervisedChild{name: name, fail: fail, runs: make(chan int, 16)}
+}
+
+func (c *supervisedChild) Start(ctx context.Context) error {
+	c.mu.Lock()
+	c.count++
+	run := c.count
+	c.mu.Unlock()
+	select {
+	case c.runs <- run:
+	default:
+	}
+
+	if c.fail != nil {
+		if err := c.fail(run); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (c *supervisedChild) Stop(context.Context) error { return nil }
+func (c *supervisedChild) Name() string               { return c.name }
+func (c *supervisedChild) LongRunning() bool          { return true }
+
+func (c *supervisedChild) startCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// waitRun 等待子组件第 run 次运行
+func (c *supervisedChild) waitRun(t *testing.T, run int) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-c.runs:
+			if got >= run {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("child '%s' was not started %d times", c.name, run)
+		}
+	}
+}
+
+// failFirst 只在第一次运行时失败
+func failFirst(run int) error {
+	if run == 1 {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func TestSupervisorRestartIntensityExceeded(t *testing.T) {
+	errBoom := errors.New("boom")
+	failing := newSupervisedChild("failing", func(int) error { return errBoom })
+	healthy := newSupervisedChild("healthy", nil)
+	s := NewSupervisor(slog.New(slog.DiscardHandler), "workers", []lifecycle.Component{healthy, failing},
+		WithBackoff(time.Millisecond, time.Millisecond), WithMaxRestarts(3, time.Minute))
+
+	var err error
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return after exceeding the restart intensity")
+	}
+
+	if !errors.Is(err, errBoom) || !strings.Contains(err.Error(), "restart intensity exceeded") {
+		t.Fatalf("Start() error = %v, want restart intensity exceeded wrapping %v", err, errBoom)
+	}
+	// 首次启动加 3 次重启，第 4 次失败超出重启强度
+	if got := failing.startCount(); got != 4 {
+		t.Fatalf("failing child started %d times, want 4", got)
+	}
+	if got := s.RestartCounts()["failing"]; got != 3 {
+		t.Fatalf("RestartCounts()[failing] = %d, want 3", got)
+	}
+
+	s.mu.Lock()
+	running := s.children[0].running
+	s.mu.Unlock()
+	if running {
+		t.Fatal("healthy child still running after the supervisor gave up")
+	}
+}
+
+func TestSupervisorRestartIntensityWindow(t *testing.T) {
+	// 每次重启前的退避时间都超过时间窗口，窗口内最多只有一次重启
+	failing := newSupervisedChild("failing", func(run int) error {
+		if run <= 4 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	s := NewSupervisor(slog.New(slog.DiscardHandler), "workers", []lifecycle.Component{failing},
+		WithMaxRestarts(1, 20*time.Millisecond), WithBackoff(30*time.Millisecond, 30*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Start(ctx) }()
+
+	failing.waitRun(t, 5)
+}
+
+func TestSupervisorStrategies(t *testing.T) {
+	tests := []struct {
+		strategy  Strategy
+		wantOther int
+	}{
+		{strategy: OneForOne, wantOther: 0},
+		{strategy: OneForAll, wantOther: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			failing := newSupervisedChild("failing", failFirst)
+			other := newSupervisedChild("other", nil)
+			s := NewSupervisor(slog.New(slog.DiscardHandler), "workers", []lifecycle.Component{other, failing},
+				WithBackoff(time.Millisecond, time.Millisecond), WithStrategy(tt.strategy))
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- s.Start(ctx) }()
+
+			failing.waitRun(t, 2)
+			other.waitRun(t, tt.wantOther+1)
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			if got := other.startCount(); got != tt.wantOther+1 {
+				t.Fatalf("other child started %d times, want %d", got, tt.wantOther+1)
+			}
+			if counts := s.RestartCounts(); counts["failing"] != 1 || counts["other"] != tt.wantOther {
+				t.Fatalf("RestartCounts() = %v, want failing: 1, other: %d", counts, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestSupervisorChildrenReportedByApp(t *testing.T) {
+	failing := newSupervisedChild("failing", failFirst)
+	s := NewSupervisor(slog.New(slog.DiscardHandler), "workers", []lifecycle.Component{failing},
+		WithBackoff(time.Millisecond, time.Millisecond))
+	stop := make(chan struct{})
+	errStop := errors.New("stop")
+	stopper := newTestComponent("stopper", true, func(context.Context) error {
+		<-stop
+		return errStop
+	})
+	a := newTestApp(t, WithComponents(s, stopper), WithStartupWindow(10*time.Millisecond))
+	done := make(chan error, 1)
+	go func() { done <- a.Run() }()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	// 子组件重启并就绪后 WaitRunning 才返回
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.WaitRunning(ctx); err != nil {
+		t.Fatalf("WaitRunning() error = %v", err)
+	}
+
+	var child *ComponentStatus
+	for _, status := range a.Status() {
+		if status.Name == "failing" {
+			child = &status
+		}
+	}
+	if child == nil || child.State != StateRunning || child.Restarts != 1 {
+		t.Fatalf("child status = %+v, want running after 1 restart", child)
+	}
+	if got := s.RestartCounts()["failing"]; got != 1 {
+		t.Fatalf("RestartCounts()[failing] = %d, want the restarts of App.Status", got)
+	}
+}
+
+func TestSupervisorChildRestartDegradesApp(t *testing.T) {
+	failing := newSupervisedChild("failing", func(int) error { return errors.New("boom") })
+	s := NewSupervisor(slog.New(slog.DiscardHandler), "workers", []lifecycle.Component{failing},
+		WithBackoff(time.Hour, time.Hour))
+	stop := make(chan struct{})
+	stopper := newTestComponent("stopper", true, func(context.Context) error {
+		<-stop
+		return errors.New("stop")
+	})
+	a := newTestApp(t, WithComponents(s, stopper), WithStartupWindow(10*time.Millisecond))
+
+	// 子组件失败，其余组件就绪
+	changed := make(chan ComponentStatus, 8)
+	a.OnStarted(func(status ComponentStatus) { changed <- status })
+	a.OnFailed(func(status ComponentStatus) { changed <- status })
+	done := make(chan error, 1)
+	go func() { done <- a.Run() }()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	for pending := 3; pending > 0; pending-- {
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("components did not start, or the supervised child did not fail")
+		}
+	}
+
+	// 子组件在退避期间等待重启，应用降级但仍可提供服务
+	if err := a.checkComponents(context.Background()); !errors.Is(err, health.ErrDegraded) {
+		t.Fatalf("checkComponents() error = %v, want %v", err, health.ErrDegraded)
+	}
+}
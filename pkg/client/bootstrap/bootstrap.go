@@ -0,0 +1,227 @@
+// Package bootstrap 根据标准配置文件（configs/config.yaml）中的 mysql、redis、mongodb、
+// kafka 和 asynq 配置段初始化客户端实例（以及配置中启用的 asynq 服务端和调度器），
+// 并把清理函数注册到 app.App 中。
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/yanking/gomicro/pkg/app"
+	"github.com/yanking/gomicro/pkg/client/database"
+	"github.com/yanking/gomicro/pkg/client/mq"
+)
+
+// Config 对应标准配置文件中的客户端配置段，可以通过 squash 嵌入到服务配置中：
+//
+//	type Config struct {
+//	  bootstrap.Config `mapstructure:",squash"`
+//	  Server ServerConfig `mapstructure:"server"`
+//	}
+type Config struct {
+	MySQL   []*database.MySQLOptions   `mapstructure:"mysql" validate:"dive" desc:"MySQL instances"`
+	Redis   []*database.RedisOptions   `mapstructure:"redis" validate:"dive" desc:"Redis instances"`
+	MongoDB []*database.MongoDBOptions `mapstructure:"mongodb" validate:"dive" desc:"MongoDB instances"`
+	Kafka   []*mq.KafkaOptions         `mapstructure:"kafka" validate:"dive" desc:"Kafka instances"`
+	Asynq   []*mq.AsynqOptions         `mapstructure:"asynq" validate:"dive" desc:"asynq instances: a client each, and a server or scheduler if enabled"`
+}
+
+// Report 记录已创建的客户端实例名称
+type Report struct {
+	MySQL   []string
+	Redis   []string
+	MongoDB []string
+	Kafka   []string
+	Asynq   []string
+	// AsynqServers 和 AsynqSchedulers 是同时创建了服务端或调度器的 asynq 实例，
+	// 它们也包含在 Asynq 中，随客户端一起关闭
+	AsynqServers    []string
+	AsynqSchedulers []string
+}
+
+// Close 按与创建相反的顺序关闭报告中的所有实例
+func (r *Report) Close(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.closers() {
+		if err := c.fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// clientCloser 是一类客户端的清理函数
+type clientCloser struct {
+	name string
+	fn   app.Close
+}
+
+// closers 返回报告中各类客户端的清理函数，顺序与创建顺序相反
+func (r *Report) closers() []clientCloser {
+	var closers []clientCloser
+	if len(r.Asynq) > 0 {
+		names := r.Asynq
+		closers = append(closers, clientCloser{"asynq", func(ctx context.Context) error {
+			return mq.CloseAsynq(ctx, names...)
+		}})
+	}
+	if len(r.Kafka) > 0 {
+		names := r.Kafka
+		closers = append(closers, clientCloser{"kafka", func(ctx context.Context) error {
+			return errors.Join(mq.CloseKafkaProducer(ctx, names...), mq.CloseKafkaConsumer(ctx, names...))
+		}})
+	}
+	if len(r.MongoDB) > 0 {
+		names := r.MongoDB
+		closers = append(closers, clientCloser{"mongodb", func(ctx context.Context) error {
+			return database.CloseMongoDB(ctx, names...)
+		}})
+	}
+	if len(r.Redis) > 0 {
+		names := r.Redis
+		closers = append(closers, clientCloser{"redis", func(ctx context.Context) error {
+			return database.CloseRedis(ctx, names...)
+		}})
+	}
+	if len(r.MySQL) > 0 {
+		names := r.MySQL
+		closers = append(closers, clientCloser{"mysql", func(ctx context.Context) error {
+			return database.CloseMySQL(ctx, names...)
+		}})
+	}
+	return closers
+}
+
+// Option 定义初始化选项
+type Option func(*options)
+
+type options struct {
+	logger *slog.Logger
+}
+
+// WithLogger 设置客户端使用的日志记录器，未在配置中单独设置 Logger 的实例都会使用它，
+// 同时用于输出初始化日志，默认为 slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// Init 依次初始化 MySQL、Redis、MongoDB、Kafka 和 Asynq 实例。
+// 每个 asynq 实例都会创建客户端，设置了 server 或 scheduler.enabled 时还会创建服务端或调度器；
+// 服务端和调度器只被创建而不会启动，需要通过 mq.GetAsynqServer、mq.GetScheduler
+// 获取后注册处理函数或定时任务再启动，关闭时随客户端一起停止。
+// a 不为 nil 时，各类客户端的清理函数会注册到应用的关闭流程中，名称为
+// "client:mysql" 等；a 为 nil 时由调用方通过 Report.Close 关闭。
+// 任一实例初始化失败时，已创建的实例会被关闭并返回错误。
+func Init(ctx context.Context, a *app.App, cfg *Config, opts ...Option) (*Report, error) {
+	o := &options{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	logger := o.logger.With(slog.String("component", "bootstrap"))
+
+	report := &Report{}
+	if cfg == nil {
+		return report, nil
+	}
+
+	if err := initClients(ctx, cfg, o.logger, report); err != nil {
+		if closeErr := report.Close(ctx); closeErr != nil {
+			logger.Error("Failed to close clients after bootstrap failure", slog.Any("error", closeErr))
+		}
+		return nil, err
+	}
+
+	if a != nil {
+		for _, c := range report.closers() {
+			a.RegisterCloseWithOptions(c.fn, app.WithCloseName("client:"+c.name))
+		}
+	}
+
+	logger.Info("Clients initialized",
+		slog.Any("mysql", report.MySQL),
+		slog.Any("redis", report.Redis),
+		slog.Any("mongodb", report.MongoDB),
+		slog.Any("kafka", report.Kafka),
+		slog.Any("asynq", report.Asynq),
+		slog.Any("asynq_servers", report.AsynqServers),
+		slog.Any("asynq_schedulers", report.AsynqSchedulers),
+	)
+	return report, nil
+}
+
+// initClients 初始化所有实例，并把创建成功的实例记录到 report 中
+func initClients(ctx context.Context, cfg *Config, logger *slog.Logger, report *Report) error {
+	for _, opt := range cfg.MySQL {
+		if opt.Logger == nil {
+			opt.Logger = logger
+		}
+		if _, err := database.InitMySQL(opt); err != nil {
+			return fmt.Errorf("failed to initialize MySQL instance '%s': %w", opt.Instance, err)
+		}
+		report.MySQL = append(report.MySQL, opt.Instance)
+	}
+
+	for _, opt := range cfg.Redis {
+		if opt.Logger == nil {
+			opt.Logger = logger
+		}
+		if _, err := database.InitRedisWithContext(ctx, opt); err != nil {
+			return fmt.Errorf("failed to initialize Redis instance '%s': %w", opt.Instance, err)
+		}
+		report.Redis = append(report.Redis, opt.Instance)
+	}
+
+	for _, opt := range cfg.MongoDB {
+		if opt.Logger == nil {
+			opt.Logger = logger
+		}
+		if _, err := database.InitMongoDBWithContext(ctx, opt); err != nil {
+			return fmt.Errorf("failed to initialize MongoDB instance '%s': %w", opt.Instance, err)
+		}
+		report.MongoDB = append(report.MongoDB, opt.Instance)
+	}
+
+	for _, opt := range cfg.Kafka {
+		if opt.Logger == nil {
+			opt.Logger = logger
+		}
+		if err := mq.InitKafka(opt); err != nil {
+			// 生产者创建成功而消费者失败时，实例不会记录到 report 中，需要单独关闭
+			_ = mq.CloseKafkaProducer(ctx, opt.Instance)
+			return fmt.Errorf("failed to initialize Kafka instance '%s': %w", opt.Instance, err)
+		}
+		report.Kafka = append(report.Kafka, opt.Instance)
+	}
+
+	for _, opt := range cfg.Asynq {
+		if opt.Logger == nil {
+			opt.Logger = logger
+		}
+		if _, err := mq.InitAsynq(opt); err != nil {
+			return fmt.Errorf("failed to initialize asynq instance '%s': %w", opt.Instance, err)
+		}
+		// 服务端和调度器与客户端同名，由 mq.CloseAsynq 一起关闭
+		report.Asynq = append(report.Asynq, opt.Instance)
+
+		if opt.Server {
+			if _, err := mq.InitAsynqServer(opt); err != nil {
+				return fmt.Errorf("failed to initialize asynq server '%s': %w", opt.Instance, err)
+			}
+			report.AsynqServers = append(report.AsynqServers, opt.Instance)
+		}
+		if opt.Scheduler.Enabled {
+			if _, err := mq.InitScheduler(opt); err != nil {
+				return fmt.Errorf("failed to initialize asynq scheduler '%s': %w", opt.Instance, err)
+			}
+			report.AsynqSchedulers = append(report.AsynqSchedulers, opt.Instance)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MySQLChecker 返回检查指定 MySQL 实例连通性的 health.Checker，检查项名称为 "mysql:<instance>"
+func MySQLChecker(instance string) health.Checker {
+	return health.NewChecker("mysql:"+instance, func(ctx context.Context) error {
+		mu.RLock()
+		db, exists := mysqlInstances[instance]
+		mu.RUnlock()
+		if !exists {
+			return fmt.Errorf("MySQL instance '%s' not initialized", instance)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// RedisChecker 返回检查指定 Redis 实例连通性的 health.Checker，检查项名称为 "redis:<instance>"
+func RedisChecker(instance string) health.Checker {
+	return health.NewChecker("redis:"+instance, func(ctx context.Context) error {
+		redisMu.RLock()
+		client, exists := redisInstances[instance]
+		redisMu.RUnlock()
+		if !exists {
+			return fmt.Errorf("redis instance '%s' not initialized", instance)
+		}
+		return client.Ping(ctx).Err()
+	})
+}
+
+// MongoDBChecker 返回检查指定 MongoDB 实例连通性的 health.Checker，检查项名称为 "mongodb:<instance>"
+func MongoDBChecker(instance string) health.Checker {
+	return health.NewChecker("mongodb:"+instance, func(ctx context.Context) error {
+		mongoMu.RLock()
+		client, exists := mongoInstances[instance]
+		mongoMu.RUnlock()
+		if !exists {
+			return fmt.Errorf("MongoDB instance '%s' not initialized", instance)
+		}
+		return client.Ping(ctx, readpref.Primary())
+	})
+}
+
+// HealthCheckers 返回所有已初始化的 MySQL、Redis 和 MongoDB 实例的 health.Checker
+func HealthCheckers() []health.Checker {
+	var checkers []health.Checker
+	for _, instance := range GetMySQLInstances() {
+		checkers = append(checkers, MySQLChecker(instance))
+	}
+	for _, instance := range GetRedisInstances() {
+		checkers = append(checkers, RedisChecker(instance))
+	}
+	for _, instance := range GetMongoDBInstances() {
+		checkers = append(checkers, MongoDBChecker(instance))
+	}
+	return checkers
+}
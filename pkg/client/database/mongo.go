@@ -22,15 +22,19 @@ var (
 // MongoDBOptions defines options for MongoDB connection.
 type MongoDBOptions struct {
 	// Instance is the name of the MongoDB instance
-	Instance string
+	Instance string `mapstructure:"instance" validate:"required" desc:"Name of the MongoDB instance"`
 	// URI is the MongoDB connection URI
-	URI string
+	URI string `mapstructure:"uri" validate:"required,startswith=mongodb" desc:"MongoDB connection URI"`
 	// ConnectTimeout is the timeout for establishing connection
-	ConnectTimeout time.Duration
+	ConnectTimeout time.Duration `mapstructure:"connectTimeout" validate:"gte=0" desc:"Timeout for establishing the connection"`
 	// MaxPoolSize is the maximum number of connections in the connection pool
-	MaxPoolSize uint64
+	MaxPoolSize uint64 `mapstructure:"maxPoolSize" desc:"Maximum number of connections in the pool"`
+	// SlowThreshold, if set, logs only the succeeded commands taking at
+	// least the threshold, at info; failed commands are always logged at
+	// error
+	SlowThreshold time.Duration `mapstructure:"slowThreshold" validate:"gte=0" desc:"Log only commands at least this slow, at info; failed commands are always logged at error, 0 logs every command"`
 	// Logger is the slog logger for MongoDB operations
-	Logger *slog.Logger
+	Logger *slog.Logger `mapstructure:"-"`
 }
 
 // InitMongoDB initializes a single MongoDB instance.
@@ -59,7 +63,7 @@ func InitMongoDBWithContext(ctx context.Context, opts *MongoDBOptions) (*mongo.C
 	// Set up logger if provided
 	if opts.Logger != nil {
 		loggerOptions := options.Logger()
-		loggerOptions.SetSink(newMongoLogger(opts.Logger))
+		loggerOptions.SetSink(newMongoLogger(opts.Logger, opts.SlowThreshold))
 		clientOptions.SetLoggerOptions(loggerOptions)
 	}
 	client, err := mongo.Connect(ctx, clientOptions)
@@ -2,31 +2,78 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"time"
+)
+
+// Command monitoring messages and keys of the MongoDB driver
+const (
+	mongoCommandStarted   = "Command started"
+	mongoCommandSucceeded = "Command succeeded"
+	mongoKeyDurationMS    = "durationMS"
 )
 
 // mongoLogger is a logger for MongoDB operations
 type mongoLogger struct {
 	logger *slog.Logger
+	// slowThreshold, if set, separates slow commands from the others
+	slowThreshold time.Duration
 }
 
 // newMongoLogger creates a new MongoDB logger
-func newMongoLogger(logger *slog.Logger) *mongoLogger {
+func newMongoLogger(logger *slog.Logger, slowThreshold time.Duration) *mongoLogger {
 	return &mongoLogger{
-		logger: logger,
+		logger:        logger,
+		slowThreshold: slowThreshold,
 	}
 }
 
-// Info logs info level messages
+// Info logs info level messages. With a slow threshold, only succeeded
+// commands taking at least the threshold are logged; started and faster
+// succeeded commands are not.
 func (l *mongoLogger) Info(_ int, msg string, keysAndValues ...interface{}) {
+	if l.slowThreshold > 0 {
+		switch msg {
+		case mongoCommandStarted:
+			return
+		case mongoCommandSucceeded:
+			if logged, _ := slowLog(l.slowThreshold, mongoDuration(keysAndValues)); !logged {
+				return
+			}
+		}
+	}
+
+	// skip converting and redacting commands that are not logged
+	if !l.logger.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
 	l.logger.Info(msg, keysAndValuesToAttr(keysAndValues)...)
 }
 
+// mongoDuration returns the duration of a command message.
+func mongoDuration(keysAndValues []interface{}) time.Duration {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i] != mongoKeyDurationMS {
+			continue
+		}
+		switch ms := keysAndValues[i+1].(type) {
+		case int64:
+			return time.Duration(ms) * time.Millisecond
+		case int:
+			return time.Duration(ms) * time.Millisecond
+		case float64:
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	return 0
+}
+
 // Error logs error level messages
 func (l *mongoLogger) Error(err error, msg string, keysAndValues ...interface{}) {
 	attrs := keysAndValuesToAttr(keysAndValues)
-	attrs = append(attrs, slog.String("error", err.Error()))
+	attrs = append(attrs, slog.String("error", redactString(err.Error())))
 	l.logger.Error(msg, attrs...)
 }
 
@@ -39,7 +86,9 @@ func keysAndValuesToAttr(keysAndValues []interface{}) []any {
 	attrs := make([]any, 0, len(keysAndValues)/2)
 	for i := 0; i < len(keysAndValues); i += 2 {
 		if i+1 < len(keysAndValues) {
-			attrs = append(attrs, slog.Any(fmt.Sprintf("%v", keysAndValues[i]), keysAndValues[i+1]))
+			// commands and replies are logged as JSON strings
+			key := fmt.Sprintf("%v", keysAndValues[i])
+			attrs = append(attrs, slog.Any(key, redactValue(key, keysAndValues[i+1])))
 		}
 	}
 	return attrs
@@ -21,16 +21,19 @@ var (
 
 // MySQLOptions defines options for mysql database.
 type MySQLOptions struct {
-	Instance              string
-	Addr                  string
-	Username              string
-	Password              string
-	Database              string
-	MaxIdleConnections    int
-	MaxOpenConnections    int
-	MaxConnectionLifeTime time.Duration
+	Instance              string        `mapstructure:"instance" validate:"required" desc:"Name of the MySQL instance"`
+	Addr                  string        `mapstructure:"addr" validate:"required,hostname_port" desc:"MySQL server address, host:port"`
+	Username              string        `mapstructure:"username" validate:"required" desc:"MySQL user name"`
+	Password              string        `mapstructure:"password" desc:"MySQL password; secret references such as ${env:...} and ${file:...} are resolved when loaded with pkg/conf"`
+	Database              string        `mapstructure:"database" validate:"required" desc:"Database name"`
+	MaxIdleConnections    int           `mapstructure:"maxIdleConnections" validate:"gte=0" desc:"Maximum number of idle connections in the pool"`
+	MaxOpenConnections    int           `mapstructure:"maxOpenConnections" validate:"gte=0" desc:"Maximum number of open connections"`
+	MaxConnectionLifeTime time.Duration `mapstructure:"maxConnectionLifeTime" validate:"gte=0" desc:"Maximum amount of time a connection may be reused"`
+	// SlowThreshold, if set, logs only the queries taking at least the
+	// threshold, at info; failed queries are always logged at error
+	SlowThreshold time.Duration `mapstructure:"slowThreshold" validate:"gte=0" desc:"Log only queries at least this slow, at info; failed queries are always logged at error, 0 logs every query"`
 	// SlogLogger is the slog logger for MySQL operations
-	Logger *slog.Logger
+	Logger *slog.Logger `mapstructure:"-"`
 }
 
 // DSN return DSN from MySQLOptions.
@@ -53,7 +56,8 @@ func InitMySQL(opts *MySQLOptions) (*gorm.DB, error) {
 	}
 	if opts.Logger != nil {
 		gormConfig.Logger = &mysqlLogger{
-			logger: opts.Logger.With(slog.String("component", "mysql")),
+			logger:        opts.Logger.With(slog.String("component", "mysql")),
+			slowThreshold: opts.SlowThreshold,
 		}
 	}
 
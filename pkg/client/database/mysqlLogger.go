@@ -13,6 +13,8 @@ var _ logger.Interface = (*mysqlLogger)(nil)
 
 type mysqlLogger struct {
 	logger *slog.Logger
+	// slowThreshold, if set, separates slow queries from the others
+	slowThreshold time.Duration
 }
 
 // LogMode 设置日志级别
@@ -21,36 +23,48 @@ func (m *mysqlLogger) LogMode(_ logger.LogLevel) logger.Interface {
 }
 
 // Info 记录 info 级别日志
-func (m *mysqlLogger) Info(_ context.Context, msg string, data ...interface{}) {
-	m.logger.Info(fmt.Sprintf(msg, data...))
+func (m *mysqlLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	m.logger.InfoContext(ctx, redactString(fmt.Sprintf(msg, data...)))
 }
 
 // Warn 记录 warning 级别日志
-func (m *mysqlLogger) Warn(_ context.Context, msg string, data ...interface{}) {
-	m.logger.Warn(fmt.Sprintf(msg, data...))
+func (m *mysqlLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	m.logger.WarnContext(ctx, redactString(fmt.Sprintf(msg, data...)))
 }
 
 // Error 记录 error 级别日志
-func (m *mysqlLogger) Error(_ context.Context, msg string, data ...interface{}) {
-	m.logger.Error(fmt.Sprintf(msg, data...))
+func (m *mysqlLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	m.logger.ErrorContext(ctx, redactString(fmt.Sprintf(msg, data...)))
 }
 
-// Trace 记录 SQL 执行轨迹
-func (m *mysqlLogger) Trace(_ context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+// Trace 记录 SQL 执行轨迹；设置了慢查询阈值时，只记录达到阈值的查询和出错的查询
+func (m *mysqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
 	elapsed := time.Since(begin)
-	sql, rows := fc()
 
 	if err != nil {
-		m.logger.Error("MySQL query error",
-			slog.String("sql", sql),
+		sql, rows := fc()
+		m.logger.ErrorContext(ctx, "MySQL query error",
+			// 插值后的 SQL 中可能包含密码、邮箱等敏感数据
+			slog.String("sql", redactString(sql)),
 			slog.Int64("rows", rows),
 			slog.Duration("duration", elapsed),
-			slog.String("error", err.Error()))
+			slog.String("error", redactString(err.Error())))
+		return
+	}
+
+	logged, slow := slowLog(m.slowThreshold, elapsed)
+	// 跳过不会输出的查询，避免生成和脱敏 SQL 的开销
+	if !logged || !m.logger.Enabled(ctx, slog.LevelInfo) {
 		return
 	}
+	msg := "MySQL query executed"
+	if slow {
+		msg = "MySQL slow query"
+	}
 
-	m.logger.Info("MySQL query executed",
-		slog.String("sql", sql),
+	sql, rows := fc()
+	m.logger.InfoContext(ctx, msg,
+		slog.String("sql", redactString(sql)),
 		slog.Int64("rows", rows),
 		slog.Duration("duration", elapsed))
 }
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	kitlog "github.com/yanking/gomicro/pkg/logger"
+)
+
+// redactString removes sensitive data from logged text with the default redactor.
+func redactString(s string) string {
+	return kitlog.GetRedactor().String(s)
+}
+
+// redactValue removes sensitive data from a value logged under key with the default redactor.
+func redactValue(key string, v any) any {
+	return kitlog.GetRedactor().Value(key, v)
+}
+
+// redactRedisArgs formats the arguments of a redis command for logging.
+// The arguments of AUTH, and of HELLO after its AUTH option, are redacted, as
+// well as the argument following a sensitive field name, such as the value
+// in "HSET user:1 password secret". The remaining arguments are redacted as text.
+func redactRedisArgs(args []any) string {
+	redactor := kitlog.GetRedactor()
+	out := make([]string, len(args))
+	masking := false
+	maskNext := false
+	for i, arg := range args {
+		s := fmt.Sprint(arg)
+		switch {
+		case masking, maskNext:
+			out[i] = kitlog.RedactedValue
+			maskNext = false
+			continue
+		case i == 0 && strings.EqualFold(s, "auth"):
+			masking = true
+		case i > 0 && strings.EqualFold(s, "auth") && strings.EqualFold(fmt.Sprint(args[0]), "hello"):
+			masking = true
+		case i > 0 && redactor.IsSensitiveKey(s):
+			maskNext = true
+		}
+		out[i] = redactor.String(s)
+	}
+	return "[" + strings.Join(out, " ") + "]"
+}
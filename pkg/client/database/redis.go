@@ -28,26 +28,30 @@ var (
 // Otherwise, the first address (Addrs[0]) will be used as single-node.
 type RedisOptions struct {
 	// Instance is the name of the redis instance
-	Instance string
+	Instance string `mapstructure:"instance" validate:"required" desc:"Name of the Redis instance"`
 	// Addrs is a list of redis addresses. Provide at least one address.
 	// If multiple addresses are provided, cluster mode will be used automatically.
-	Addrs         []string
-	Username      string
-	Password      string
-	DB            int
-	PoolSize      int
-	MinIdleConns  int
-	DialTimeout   time.Duration
-	ReadTimeout   time.Duration
-	WriteTimeout  time.Duration
-	EnableTLS     bool
-	TLSSkipVerify bool
-	TLSCAFile     string
-	TLSCertFile   string
-	TLSKeyFile    string
-	TLSServerName string
+	Addrs         []string      `mapstructure:"addrs" validate:"required,min=1,dive,hostname_port" desc:"Redis addresses; more than one address enables cluster mode"`
+	Username      string        `mapstructure:"username" desc:"Redis user name"`
+	Password      string        `mapstructure:"password" desc:"Redis password; secret references such as ${env:...} and ${file:...} are resolved when loaded with pkg/conf"`
+	DB            int           `mapstructure:"db" validate:"gte=0" desc:"Redis database number, ignored in cluster mode"`
+	PoolSize      int           `mapstructure:"poolSize" validate:"gte=0" desc:"Maximum number of socket connections"`
+	MinIdleConns  int           `mapstructure:"minIdleConns" validate:"gte=0" desc:"Minimum number of idle connections"`
+	DialTimeout   time.Duration `mapstructure:"dialTimeout" validate:"gte=0" desc:"Timeout for establishing new connections"`
+	ReadTimeout   time.Duration `mapstructure:"readTimeout" desc:"Timeout for socket reads"`
+	WriteTimeout  time.Duration `mapstructure:"writeTimeout" desc:"Timeout for socket writes"`
+	EnableTLS     bool          `mapstructure:"enableTLS" desc:"Connect over TLS"`
+	TLSSkipVerify bool          `mapstructure:"tlsSkipVerify" desc:"Skip verification of the server certificate"`
+	TLSCAFile     string        `mapstructure:"tlsCAFile" desc:"CA certificate file used to verify the server"`
+	TLSCertFile   string        `mapstructure:"tlsCertFile" desc:"Client certificate file"`
+	TLSKeyFile    string        `mapstructure:"tlsKeyFile" desc:"Client private key file"`
+	TLSServerName string        `mapstructure:"tlsServerName" desc:"Server name used to verify the certificate"`
+	// SlowThreshold, if set, logs only the commands, pipelines and dials
+	// taking at least the threshold, at info; failures are always logged at
+	// error
+	SlowThreshold time.Duration `mapstructure:"slowThreshold" validate:"gte=0" desc:"Log only commands, pipelines and dials at least this slow, at info; failures are always logged at error, 0 logs every command"`
 	// Logger is the slog logger for Redis operations
-	Logger *slog.Logger
+	Logger *slog.Logger `mapstructure:"-"`
 }
 
 // InitRedis initializes a single redis instance.
@@ -79,7 +83,7 @@ func InitRedisWithContext(ctx context.Context, opts *RedisOptions) (redis.Univer
 	// Add logging hook with provided logger or default logger
 	if opts.Logger != nil {
 		logger := opts.Logger.With(slog.String("component", "redis"))
-		client.AddHook(&redisLogger{logger: logger})
+		client.AddHook(&redisLogger{logger: logger, slowThreshold: opts.SlowThreshold})
 	}
 
 	// Store the instance
@@ -15,6 +15,8 @@ var _ redis.Hook = (*redisLogger)(nil)
 
 type redisLogger struct {
 	logger *slog.Logger
+	// slowThreshold, if set, separates slow operations from the others
+	slowThreshold time.Duration
 }
 
 func (r *redisLogger) DialHook(next redis.DialHook) redis.DialHook {
@@ -25,13 +27,17 @@ func (r *redisLogger) DialHook(next redis.DialHook) redis.DialHook {
 		duration := time.Since(start)
 
 		if err != nil {
-			r.logger.Error("Redis dial failed",
+			r.logger.ErrorContext(ctx, "Redis dial failed",
 				slog.String("network", network),
 				slog.String("addr", addr),
 				slog.Duration("duration", duration),
-				slog.String("error", err.Error()))
-		} else {
-			r.logger.Info("Redis dial success",
+				slog.String("error", redactString(err.Error())))
+		} else if logged, slow := slowLog(r.slowThreshold, duration); logged {
+			msg := "Redis dial success"
+			if slow {
+				msg = "Redis slow dial"
+			}
+			r.logger.InfoContext(ctx, msg,
 				slog.String("network", network),
 				slog.String("addr", addr),
 				slog.Duration("duration", duration))
@@ -48,16 +54,27 @@ func (r *redisLogger) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 		err := next(ctx, cmd)
 		duration := time.Since(start)
 
-		if err != nil && !errors.Is(err, redis.Nil) {
-			r.logger.Error("Redis command failed",
+		logged, slow := slowLog(r.slowThreshold, duration)
+		failed := err != nil && !errors.Is(err, redis.Nil)
+		if !failed && (!logged || !r.logger.Enabled(ctx, slog.LevelInfo)) {
+			// skip formatting and redacting the arguments
+			return err
+		}
+
+		if failed {
+			r.logger.ErrorContext(ctx, "Redis command failed",
 				slog.String("command", cmd.Name()),
-				slog.String("args", fmt.Sprintf("%v", cmd.Args())),
+				slog.String("args", redactRedisArgs(cmd.Args())),
 				slog.Duration("duration", duration),
-				slog.String("error", err.Error()))
+				slog.String("error", redactString(err.Error())))
 		} else {
-			r.logger.Info("Redis command executed",
+			msg := "Redis command executed"
+			if slow {
+				msg = "Redis slow command"
+			}
+			r.logger.InfoContext(ctx, msg,
 				slog.String("command", cmd.Name()),
-				slog.String("args", fmt.Sprintf("%v", cmd.Args())),
+				slog.String("args", redactRedisArgs(cmd.Args())),
 				slog.Duration("duration", duration))
 		}
 
@@ -72,19 +89,29 @@ func (r *redisLogger) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.
 		err := next(ctx, cmds)
 		duration := time.Since(start)
 
+		logged, slow := slowLog(r.slowThreshold, duration)
+		failed := err != nil && !errors.Is(err, redis.Nil)
+		if !failed && (!logged || !r.logger.Enabled(ctx, slog.LevelInfo)) {
+			return err
+		}
+
 		cmdNames := make([]string, len(cmds))
 		for i, cmd := range cmds {
 			cmdNames[i] = cmd.Name()
 		}
 
-		if err != nil && !errors.Is(err, redis.Nil) {
-			r.logger.Error("Redis pipeline failed",
+		if failed {
+			r.logger.ErrorContext(ctx, "Redis pipeline failed",
 				slog.String("commands", fmt.Sprintf("%v", cmdNames)),
 				slog.Int("count", len(cmds)),
 				slog.Duration("duration", duration),
-				slog.String("error", err.Error()))
+				slog.String("error", redactString(err.Error())))
 		} else {
-			r.logger.Info("Redis pipeline executed",
+			msg := "Redis pipeline executed"
+			if slow {
+				msg = "Redis slow pipeline"
+			}
+			r.logger.InfoContext(ctx, msg,
 				slog.String("commands", fmt.Sprintf("%v", cmdNames)),
 				slog.Int("count", len(cmds)),
 				slog.Duration("duration", duration))
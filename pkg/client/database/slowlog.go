@@ -0,0 +1,17 @@
+package database
+
+import (
+	"time"
+)
+
+// slowLog reports whether a successful operation is logged and whether it is
+// slow. Without a slow threshold every operation is logged; with one, only
+// operations taking at least the threshold are logged, as slow ones. Logged
+// operations use info and failed operations are always logged at error.
+func slowLog(threshold, elapsed time.Duration) (logged, slow bool) {
+	if threshold <= 0 {
+		return true, false
+	}
+	slow = elapsed >= threshold
+	return slow, slow
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		elapsed   time.Duration
+		logged    bool
+		slow      bool
+	}{
+		{name: "no threshold", elapsed: time.Second, logged: true},
+		{name: "fast", threshold: 200 * time.Millisecond, elapsed: 10 * time.Millisecond},
+		{name: "at threshold", threshold: 200 * time.Millisecond, elapsed: 200 * time.Millisecond, logged: true, slow: true},
+		{name: "slow", threshold: 200 * time.Millisecond, elapsed: time.Second, logged: true, slow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged, slow := slowLog(tt.threshold, tt.elapsed)
+			if logged != tt.logged || slow != tt.slow {
+				t.Fatalf("slowLog() = %v, %v, want %v, %v", logged, slow, tt.logged, tt.slow)
+			}
+		})
+	}
+}
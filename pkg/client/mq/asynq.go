@@ -2,9 +2,12 @@
 package mq
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
@@ -13,7 +16,7 @@ import (
 // AsynqOptions defines options for asynq.
 type AsynqOptions struct {
 	// Instance is the name of the asynq instance
-	Instance string `mapstructure:"instance"`
+	Instance string `mapstructure:"instance" validate:"required" desc:"Name of the asynq instance"`
 
 	// Redis client options
 	Redis redis.UniversalClient `mapstructure:"-"`
@@ -22,39 +25,78 @@ type AsynqOptions struct {
 	Logger *slog.Logger `mapstructure:"-"`
 
 	// Concurrency is the maximum number of concurrent workers
-	Concurrency int `mapstructure:"concurrency"`
+	Concurrency int `mapstructure:"concurrency" validate:"gte=0" desc:"Maximum number of tasks processed concurrently by the server"`
 
 	// Queues is a list of queues to process
-	Queues map[string]int `mapstructure:"queues"`
+	Queues map[string]int `mapstructure:"queues" desc:"Queue names and their priorities"`
 
 	// RedisAddr is the address of the Redis server (for single node)
-	RedisAddr string `mapstructure:"redis_addr"`
+	RedisAddr string `mapstructure:"redis_addr" desc:"Redis address for single-node mode"`
 
 	// RedisAddrs is the addresses of the Redis servers (for cluster or sentinel)
-	RedisAddrs []string `mapstructure:"redis_addrs"`
+	RedisAddrs []string `mapstructure:"redis_addrs" desc:"Redis addresses; more than one enables cluster mode, or sentinel mode with master_name"`
 
 	// RedisDB is the Redis database number (for single node)
-	RedisDB int `mapstructure:"redis_db"`
+	RedisDB int `mapstructure:"redis_db" validate:"gte=0" desc:"Redis database number"`
 
 	// RedisUsername is the Redis username
-	RedisUsername string `mapstructure:"redis_username"`
+	RedisUsername string `mapstructure:"redis_username" desc:"Redis user name"`
 
 	// RedisPassword is the Redis password
-	RedisPassword string `mapstructure:"redis_password"`
+	RedisPassword string `mapstructure:"redis_password" desc:"Redis password; secret references such as ${env:...} and ${file:...} are resolved when loaded with pkg/conf"`
 
 	// MasterName is the Redis sentinel master name
-	MasterName string `mapstructure:"master_name"`
+	MasterName string `mapstructure:"master_name" desc:"Sentinel master name; enables sentinel mode"`
 
 	// SentinelUsername is the Redis sentinel username
-	SentinelUsername string `mapstructure:"sentinel_username"`
+	SentinelUsername string `mapstructure:"sentinel_username" desc:"Redis sentinel user name"`
 
 	// SentinelPassword is the Redis sentinel password
-	SentinelPassword string `mapstructure:"sentinel_password"`
+	SentinelPassword string `mapstructure:"sentinel_password" desc:"Redis sentinel password"`
 
-	// SchedulerOpts contains scheduler options
+	// Server creates a server for the instance when it is initialized by
+	// pkg/client/bootstrap
+	Server bool `mapstructure:"server" desc:"Create a server processing the queues when initialized by pkg/client/bootstrap; register handlers and start it via GetAsynqServer"`
+
+	// Scheduler configures the scheduler created from configuration
+	Scheduler AsynqSchedulerOptions `mapstructure:"scheduler" desc:"Scheduler created from configuration"`
+
+	// SchedulerOpts contains scheduler options. When set it takes precedence
+	// over Scheduler.
 	SchedulerOpts *asynq.SchedulerOpts `mapstructure:"-"`
 }
 
+// AsynqSchedulerOptions defines the scheduler options that can be set in the
+// configuration file.
+type AsynqSchedulerOptions struct {
+	// Enabled creates a scheduler for the instance when it is initialized by
+	// pkg/client/bootstrap
+	Enabled bool `mapstructure:"enabled" desc:"Create a scheduler when initialized by pkg/client/bootstrap; register entries and start it via GetScheduler"`
+
+	// Location is the time zone of the cron specs
+	Location string `mapstructure:"location" desc:"Time zone of the cron specs, e.g. Local or Asia/Shanghai; UTC if empty"`
+
+	// HeartbeatInterval is the interval between scheduler heartbeats
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval" validate:"gte=0" desc:"Interval between scheduler heartbeats; 10s if zero"`
+}
+
+// schedulerOpts returns the asynq scheduler options of opts.
+func (opts *AsynqOptions) schedulerOpts() (*asynq.SchedulerOpts, error) {
+	if opts.SchedulerOpts != nil {
+		return opts.SchedulerOpts, nil
+	}
+
+	schedulerOpts := &asynq.SchedulerOpts{HeartbeatInterval: opts.Scheduler.HeartbeatInterval}
+	if opts.Scheduler.Location != "" {
+		location, err := time.LoadLocation(opts.Scheduler.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduler location '%s': %w", opts.Scheduler.Location, err)
+		}
+		schedulerOpts.Location = location
+	}
+	return schedulerOpts, nil
+}
+
 // asynqInstances stores multiple asynq instances
 var (
 	asynqInstances       = make(map[string]*asynq.Client)
@@ -137,7 +179,12 @@ func InitScheduler(opts *AsynqOptions) (*asynq.Scheduler, error) {
 		return nil, err
 	}
 
-	scheduler := asynq.NewScheduler(redisOpt, opts.SchedulerOpts)
+	schedulerOpts, err := opts.schedulerOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler := asynq.NewScheduler(redisOpt, schedulerOpts)
 
 	asynqMu.Lock()
 	schedulerInstances[opts.Instance] = scheduler
@@ -152,3 +199,81 @@ func GetScheduler(name string) *asynq.Scheduler {
 	defer asynqMu.RUnlock()
 	return schedulerInstances[name]
 }
+
+// GetAsynqInstances returns the names of all asynq client instances.
+func GetAsynqInstances() []string {
+	asynqMu.RLock()
+	defer asynqMu.RUnlock()
+
+	instances := make([]string, 0, len(asynqInstances))
+	for name := range asynqInstances {
+		instances = append(instances, name)
+	}
+	return instances
+}
+
+// CloseAsynq closes the specified asynq instances: clients are closed and
+// servers and schedulers are shut down.
+// If no instances are specified, all instances will be closed.
+// The instances are removed at once; when ctx is done before the servers
+// finish their active tasks, CloseAsynq returns without waiting for them.
+func CloseAsynq(ctx context.Context, instances ...string) error {
+	servers := make(map[string]*asynq.Server)
+	schedulers := make(map[string]*asynq.Scheduler)
+	clients := make(map[string]*asynq.Client)
+
+	asynqMu.Lock()
+	// If no instances specified, close all
+	if len(instances) == 0 {
+		for name := range asynqInstances {
+			instances = append(instances, name)
+		}
+		for name := range asynqServerInstances {
+			instances = append(instances, name)
+		}
+		for name := range schedulerInstances {
+			instances = append(instances, name)
+		}
+	}
+	for _, instance := range instances {
+		if server, exists := asynqServerInstances[instance]; exists {
+			servers[instance] = server
+			delete(asynqServerInstances, instance)
+		}
+		if scheduler, exists := schedulerInstances[instance]; exists {
+			schedulers[instance] = scheduler
+			delete(schedulerInstances, instance)
+		}
+		if client, exists := asynqInstances[instance]; exists {
+			clients[instance] = client
+			delete(asynqInstances, instance)
+		}
+	}
+	asynqMu.Unlock()
+
+	// Server.Shutdown waits for active tasks, so the instances are shut down
+	// without holding asynqMu
+	done := make(chan error, 1)
+	go func() {
+		for _, server := range servers {
+			server.Shutdown()
+		}
+		for _, scheduler := range schedulers {
+			scheduler.Shutdown()
+		}
+		var errs []error
+		for name, client := range clients {
+			if err := client.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close asynq instance '%s': %w", name, err))
+			}
+		}
+		done <- errors.Join(errs...)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to close asynq instances: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,99 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/yanking/gomicro/pkg/health"
+)
+
+// KafkaChecker 返回检查指定 Kafka 实例 broker 可达性的 health.Checker，检查项名称为 "kafka:<instance>"
+// 只要有一个 broker 可以建立连接即视为健康
+func KafkaChecker(instance string) health.Checker {
+	return health.NewChecker("kafka:"+instance, func(ctx context.Context) error {
+		kafkaBrokerMu.RLock()
+		brokers, exists := kafkaBrokers[instance]
+		kafkaBrokerMu.RUnlock()
+		if !exists {
+			return fmt.Errorf("Kafka instance '%s' not initialized", instance)
+		}
+
+		var dialer net.Dialer
+		errs := make([]error, 0, len(brokers))
+		for _, broker := range brokers {
+			conn, err := dialer.DialContext(ctx, "tcp", broker)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return fmt.Errorf("Kafka instance '%s' has no brokers", instance)
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// AsynqChecker 返回检查指定 asynq 实例 Redis 连通性的 health.Checker，检查项名称为 "asynq:<instance>"
+func AsynqChecker(instance string) health.Checker {
+	return health.NewChecker("asynq:"+instance, func(_ context.Context) error {
+		asynqMu.RLock()
+		client := asynqInstances[instance]
+		server := asynqServerInstances[instance]
+		scheduler := schedulerInstances[instance]
+		asynqMu.RUnlock()
+
+		switch {
+		case client != nil:
+			return client.Ping()
+		case server != nil:
+			return server.Ping()
+		case scheduler != nil:
+			return scheduler.Ping()
+		default:
+			return fmt.Errorf("asynq instance '%s' not initialized", instance)
+		}
+	})
+}
+
+// HealthCheckers 返回所有已初始化的 Kafka 和 asynq 实例的 health.Checker
+func HealthCheckers() []health.Checker {
+	kafkaBrokerMu.RLock()
+	kafkaNames := make([]string, 0, len(kafkaBrokers))
+	for name := range kafkaBrokers {
+		kafkaNames = append(kafkaNames, name)
+	}
+	kafkaBrokerMu.RUnlock()
+
+	asynqMu.RLock()
+	asynqSet := make(map[string]struct{})
+	for name := range asynqInstances {
+		asynqSet[name] = struct{}{}
+	}
+	for name := range asynqServerInstances {
+		asynqSet[name] = struct{}{}
+	}
+	for name := range schedulerInstances {
+		asynqSet[name] = struct{}{}
+	}
+	asynqMu.RUnlock()
+
+	asynqNames := make([]string, 0, len(asynqSet))
+	for name := range asynqSet {
+		asynqNames = append(asynqNames, name)
+	}
+	sort.Strings(kafkaNames)
+	sort.Strings(asynqNames)
+
+	checkers := make([]health.Checker, 0, len(kafkaNames)+len(asynqNames))
+	for _, name := range kafkaNames {
+		checkers = append(checkers, KafkaChecker(name))
+	}
+	for _, name := range asynqNames {
+		checkers = append(checkers, AsynqChecker(name))
+	}
+	return checkers
+}
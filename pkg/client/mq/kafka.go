@@ -20,44 +20,48 @@ var (
 	kafkaProducerMu sync.RWMutex
 	// kafkaConsumerMu protects kafkaConsumers
 	kafkaConsumerMu sync.RWMutex
+	// kafkaBrokers stores the broker addresses of each Kafka instance
+	kafkaBrokers = make(map[string][]string)
+	// kafkaBrokerMu protects kafkaBrokers
+	kafkaBrokerMu sync.RWMutex
 )
 
 // KafkaOptions defines options for Kafka connection.
 type KafkaOptions struct {
 	// Instance is the name of the Kafka instance
-	Instance string
+	Instance string `mapstructure:"instance" validate:"required" desc:"Name of the Kafka instance"`
 	// Brokers is a list of Kafka broker addresses
-	Brokers []string
+	Brokers []string `mapstructure:"brokers" validate:"required,min=1,dive,hostname_port" desc:"Kafka broker addresses, host:port"`
 	// Version is the Kafka version (default: "2.1.0")
-	Version string
+	Version string `mapstructure:"version" desc:"Kafka protocol version, default 2.1.0"`
 	// Producer configuration
-	Producer *KafkaProducerOptions
+	Producer *KafkaProducerOptions `mapstructure:"producer" desc:"Producer settings; the producer is created only if set"`
 	// Consumer configuration
-	Consumer *KafkaConsumerOptions
+	Consumer *KafkaConsumerOptions `mapstructure:"consumer" desc:"Consumer settings; the consumer is created only if set"`
 	// Logger is the slog logger for Kafka operations
-	Logger *slog.Logger
+	Logger *slog.Logger `mapstructure:"-"`
 }
 
 // KafkaProducerOptions defines options for Kafka producer.
 type KafkaProducerOptions struct {
 	// MaxRetries is the maximum number of retries for sending a message
-	MaxRetries int
+	MaxRetries int `mapstructure:"maxRetries" desc:"Maximum number of retries for sending a message"`
 	// RetryBackoff is the time to wait between retries
-	RetryBackoff time.Duration
+	RetryBackoff time.Duration `mapstructure:"retryBackoff" desc:"Time to wait between retries"`
 	// RequiredAcks is the number of acks required (default: WaitForLocal)
-	RequiredAcks sarama.RequiredAcks
+	RequiredAcks sarama.RequiredAcks `mapstructure:"requiredAcks" desc:"Acks required: 0 none, 1 leader, -1 all in-sync replicas"`
 	// Timeout is the maximum time to wait for a response
-	Timeout time.Duration
+	Timeout time.Duration `mapstructure:"timeout" desc:"Maximum time to wait for a broker response"`
 }
 
 // KafkaConsumerOptions defines options for Kafka consumer.
 type KafkaConsumerOptions struct {
 	// GroupID is the consumer group ID
-	GroupID string
+	GroupID string `mapstructure:"groupID" desc:"Consumer group ID"`
 	// OffsetInitial is the initial offset position (default: Newest)
-	OffsetInitial int64
+	OffsetInitial int64 `mapstructure:"offsetInitial" desc:"Initial offset: -1 newest, -2 oldest"`
 	// Timeout is the maximum time to wait for a message
-	Timeout time.Duration
+	Timeout time.Duration `mapstructure:"timeout" desc:"Maximum time to wait for a message"`
 }
 
 // InitKafka initializes a single Kafka instance for both producer and consumer.
@@ -99,6 +103,10 @@ func InitKafka(opts *KafkaOptions) error {
 		kafkaConsumerMu.Unlock()
 	}
 
+	kafkaBrokerMu.Lock()
+	kafkaBrokers[opts.Instance] = opts.Brokers
+	kafkaBrokerMu.Unlock()
+
 	return nil
 }
 
@@ -285,6 +293,7 @@ func closeKafkaComponents[T any](mu *sync.RWMutex,
 // closeKafkaProducers closes specified Kafka producer instances.
 // If no instances are specified, all producer instances will be closed.
 func closeKafkaProducers(_ context.Context, instances ...string) error {
+	defer pruneKafkaBrokers()
 	return closeKafkaComponents(&kafkaProducerMu, kafkaProducers,
 		func(p sarama.SyncProducer) error { return p.Close() },
 		"producer", instances...)
@@ -293,11 +302,36 @@ func closeKafkaProducers(_ context.Context, instances ...string) error {
 // closeKafkaConsumers closes specified Kafka consumer instances.
 // If no instances are specified, all consumer instances will be closed.
 func closeKafkaConsumers(_ context.Context, instances ...string) error {
+	defer pruneKafkaBrokers()
 	return closeKafkaComponents(&kafkaConsumerMu, kafkaConsumers,
 		func(c sarama.Consumer) error { return c.Close() },
 		"consumer", instances...)
 }
 
+// pruneKafkaBrokers forgets the brokers of instances whose producer and
+// consumer are both closed, so that they are no longer health checked.
+func pruneKafkaBrokers() {
+	open := make(map[string]bool)
+	kafkaProducerMu.RLock()
+	for name := range kafkaProducers {
+		open[name] = true
+	}
+	kafkaProducerMu.RUnlock()
+	kafkaConsumerMu.RLock()
+	for name := range kafkaConsumers {
+		open[name] = true
+	}
+	kafkaConsumerMu.RUnlock()
+
+	kafkaBrokerMu.Lock()
+	defer kafkaBrokerMu.Unlock()
+	for name := range kafkaBrokers {
+		if !open[name] {
+			delete(kafkaBrokers, name)
+		}
+	}
+}
+
 // CloseKafkaProducer closes specified Kafka producer instances.
 // If no instances are specified, all producer instances will be closed.
 func CloseKafkaProducer(ctx context.Context, instances ...string) error {
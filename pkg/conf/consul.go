@@ -0,0 +1,84 @@
+package conf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ConsulKV reads keys from the Consul KV store over its HTTP API.
+type ConsulKV struct {
+	addr string
+	opts kvOptions
+	// wait is the maximum duration of a blocking query
+	wait string
+}
+
+// NewConsulKV creates a Consul client for an agent address such as
+// "http://127.0.0.1:8500".
+func NewConsulKV(addr string, opts ...KVOption) *ConsulKV {
+	return &ConsulKV{addr: strings.TrimRight(addr, "/"), opts: newKVOptions(opts), wait: "5m"}
+}
+
+// Name implements KV.
+func (c *ConsulKV) Name() string {
+	return "consul"
+}
+
+// Get implements KV. The revision is the X-Consul-Index of the key.
+func (c *ConsulKV) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	return c.get(ctx, key, nil)
+}
+
+// Watch implements KV with a Consul blocking query.
+func (c *ConsulKV) Watch(ctx context.Context, key string, revision uint64) (uint64, error) {
+	query := map[string]string{
+		"index": strconv.FormatUint(revision, 10),
+		"wait":  c.wait,
+	}
+	_, next, err := c.get(ctx, key, query)
+	if errors.Is(err, ErrKeyNotFound) {
+		// a deleted key is a change; the reload reports the missing key
+		return next, nil
+	}
+	return next, err
+}
+
+func (c *ConsulKV) get(ctx context.Context, key string, query map[string]string) ([]byte, uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/v1/kv/"+strings.TrimLeft(key, "/"), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := req.URL.Query()
+	q.Set("raw", "")
+	for k, v := range query {
+		q.Set(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+	if c.opts.token != "" {
+		req.Header.Set("X-Consul-Token", c.opts.token)
+	}
+
+	resp, err := c.opts.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		value, err := io.ReadAll(resp.Body)
+		return value, index, err
+	case http.StatusNotFound:
+		return nil, index, fmt.Errorf("%w: consul key %s", ErrKeyNotFound, key)
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("consul %s: %s: %s", req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+}
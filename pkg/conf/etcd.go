@@ -0,0 +1,163 @@
+package conf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EtcdKV reads keys from etcd v3 through its HTTP/JSON gateway.
+type EtcdKV struct {
+	endpoints []string
+	opts      kvOptions
+}
+
+// NewEtcdKV creates an etcd client for endpoints such as "http://127.0.0.1:2379".
+// Endpoints are tried in order until one responds.
+func NewEtcdKV(endpoints []string, opts ...KVOption) *EtcdKV {
+	trimmed := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		trimmed[i] = strings.TrimRight(endpoint, "/")
+	}
+	return &EtcdKV{endpoints: trimmed, opts: newKVOptions(opts)}
+}
+
+// Name implements KV.
+func (e *EtcdKV) Name() string {
+	return "etcd"
+}
+
+// etcdKeyValue is a key-value pair in etcd gateway responses; int64 fields are
+// encoded as strings and bytes as base64.
+type etcdKeyValue struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+// Get implements KV. The revision is the key's modification revision.
+func (e *EtcdKV) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	var resp struct {
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+	if err := e.post(ctx, "/v3/kv/range", map[string]any{"key": []byte(key)}, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&resp)
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, 0, fmt.Errorf("%w: etcd key %s", ErrKeyNotFound, key)
+	}
+	revision, _ := strconv.ParseUint(resp.Kvs[0].ModRevision, 10, 64)
+	return resp.Kvs[0].Value, revision, nil
+}
+
+// Watch implements KV. It streams events for key after revision and returns
+// the revision of the first change.
+func (e *EtcdKV) Watch(ctx context.Context, key string, revision uint64) (uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	request := map[string]any{
+		"create_request": map[string]any{
+			"key":            []byte(key),
+			"start_revision": strconv.FormatUint(revision+1, 10),
+		},
+	}
+
+	next := revision
+	err := e.post(ctx, "/v3/watch", request, func(body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		for {
+			var msg struct {
+				Result struct {
+					Events []struct {
+						Kv etcdKeyValue `json:"kv"`
+					} `json:"events"`
+					Canceled     bool   `json:"canceled"`
+					CancelReason string `json:"cancel_reason"`
+				} `json:"result"`
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := decoder.Decode(&msg); err != nil {
+				return err
+			}
+			if msg.Error != nil {
+				return fmt.Errorf("etcd watch: %s", msg.Error.Message)
+			}
+			if msg.Result.Canceled {
+				return fmt.Errorf("etcd watch canceled: %s", msg.Result.CancelReason)
+			}
+			for _, event := range msg.Result.Events {
+				if rev, err := strconv.ParseUint(event.Kv.ModRevision, 10, 64); err == nil && rev > next {
+					next = rev
+				}
+			}
+			if next != revision {
+				return nil
+			}
+		}
+	})
+	return next, err
+}
+
+// post sends a JSON request to the first endpoint that responds without a
+// server error and passes the response body to handle.
+func (e *EtcdKV) post(ctx context.Context, path string, request any, handle func(io.Reader) error) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	if len(e.endpoints) == 0 {
+		return errors.New("etcd: no endpoints")
+	}
+
+	var errs []error
+	for _, endpoint := range e.endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if e.opts.token != "" {
+			req.Header.Set("Authorization", e.opts.token)
+		}
+
+		resp, err := e.opts.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		retry := false
+		err = func() error {
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusOK {
+				// a failing member is skipped, a rejected request fails on every member
+				retry = resp.StatusCode >= http.StatusInternalServerError
+				msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+				return fmt.Errorf("etcd %s: %s: %s", endpoint+path, resp.Status, bytes.TrimSpace(msg))
+			}
+			return handle(resp.Body)
+		}()
+		if retry {
+			errs = append(errs, err)
+			continue
+		}
+		return err
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package conf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEtcdKVFailover(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"kvs":[{"key":"YQ==","value":"MQ==","mod_revision":"7"}]}`))
+	}))
+	defer healthy.Close()
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "server error tries the next endpoint", status: http.StatusServiceUnavailable},
+		{name: "client error fails at once", status: http.StatusUnauthorized, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer failing.Close()
+
+			value, revision, err := NewEtcdKV([]string{failing.URL, healthy.URL}).Get(context.Background(), "a")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Get() error = nil, want the error of the first endpoint")
+				}
+				return
+			}
+			if err != nil || string(value) != "1" || revision != 7 {
+				t.Fatalf("Get() = %q, %d, %v, want 1, 7 from the healthy endpoint", value, revision, err)
+			}
+		})
+	}
+}
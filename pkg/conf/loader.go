@@ -0,0 +1,377 @@
+package conf
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// Loader merges an ordered list of configuration sources into a struct.
+//
+// Sources are applied in the order they were added and later sources override
+// earlier ones, so the usual order is from lowest to highest precedence:
+//
+//	loader := conf.NewLoader().
+//	  Defaults().                       // `default:"..."` struct tags
+//	  File("configs/config.yaml").      // base file
+//	  Overlay(os.Getenv("APP_ENV")).    // configs/config.prod.yaml, optional
+//	  Dir("configs/conf.d").            // fragments, sorted by file name
+//	  Env("ORDER").                     // ORDER_SERVER_PORT, ...
+//	  Flags(flag.CommandLine)           // -server.port=8081
+//
+//	if err := loader.Load(&cfg); err != nil {
+//	  log.Fatal(err)
+//	}
+//	fmt.Println(loader.Origin("server.port")) // e.g. "flags"
+//
+// A Loader is not safe for concurrent use.
+type Loader struct {
+	sources []Source
+	// lastFile is the most recently added file, used by Overlay.
+	lastFile string
+	origins  map[string]string
+	// defaults is a copy of the target of the first Load, decoded onto by
+	// every Load so that reloads start from the caller defaults.
+	defaults reflect.Value
+}
+
+// NewLoader creates an empty Loader.
+func NewLoader() *Loader {
+	return &Loader{}
+}
+
+// Add appends a custom source.
+func (l *Loader) Add(source Source) *Loader {
+	l.sources = append(l.sources, source)
+	return l
+}
+
+// Defaults adds the values of `default:"..."` struct tags of the target struct.
+func (l *Loader) Defaults() *Loader {
+	return l.Add(&defaultsSource{})
+}
+
+// File adds a required configuration file.
+func (l *Loader) File(path string) *Loader {
+	l.lastFile = path
+	return l.Add(FileSource(path, false))
+}
+
+// OptionalFile adds a configuration file that is skipped if it does not exist.
+func (l *Loader) OptionalFile(path string) *Loader {
+	l.lastFile = path
+	return l.Add(FileSource(path, true))
+}
+
+// Overlay adds the optional environment-specific overlay of the most recently
+// added file, e.g. config.prod.yaml for config.yaml and env "prod".
+// It does nothing if env is empty or no file has been added.
+func (l *Loader) Overlay(env string) *Loader {
+	if env == "" || l.lastFile == "" {
+		return l
+	}
+	return l.Add(FileSource(overlayPath(l.lastFile, env), true))
+}
+
+// Dir adds every configuration file in dir, in file name order.
+// A missing directory is ignored.
+func (l *Loader) Dir(dir string) *Loader {
+	return l.Add(&dirSource{dir: dir})
+}
+
+// Remote adds the configuration document stored in key of a key-value store
+// such as etcd or Consul. Watcher and Reloader reload when the key changes.
+func (l *Loader) Remote(kv KV, key string, opts ...RemoteOption) *Loader {
+	return l.Add(RemoteSource(kv, key, opts...))
+}
+
+// Env adds environment variables named <prefix>_<KEY> for every known key.
+// Known keys are the fields of the target struct and the keys supplied by
+// earlier sources.
+func (l *Loader) Env(prefix string) *Loader {
+	return l.Add(&envSource{prefix: prefix})
+}
+
+// Flags adds the flags of fs that were explicitly set on the command line and
+// whose name matches a known key. fs must already be parsed.
+func (l *Loader) Flags(fs *flag.FlagSet) *Loader {
+	return l.Add(&flagSource{fs: fs})
+}
+
+// Load merges all sources, resolves secret references such as ${env:DB_PASS},
+// decodes the result into a copy of obj and validates its
+// `validate:"..."` struct tags. obj is only replaced when
+// decoding and validation succeed, so a failed reload keeps the old values.
+// Fields that no source sets keep the values obj had at the first Load of
+// the Loader, so defaults assigned by the caller survive, and a key removed
+// from the sources reverts to its default on a reload. Maps are merged the
+// same way: default entries missing from the sources are kept. obj must be a
+// non-nil pointer.
+func (l *Loader) Load(obj any) error {
+	return l.LoadContext(context.Background(), obj)
+}
+
+// LoadContext is like Load but passes ctx to the sources.
+func (l *Loader) LoadContext(ctx context.Context, obj any) error {
+	target := reflect.ValueOf(obj)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("conf: obj must be a non-nil pointer, got %T", obj)
+	}
+
+	v, origins, err := l.merge(ctx, target.Type())
+	if err != nil {
+		return err
+	}
+
+	if err := resolveSecrets(ctx, v); err != nil {
+		return err
+	}
+
+	// decode into a deep copy of the defaults, so that a failed decode or
+	// validation leaves obj untouched and values of the previous load that
+	// no source sets any more are dropped
+	if !l.defaults.IsValid() || l.defaults.Type() != target.Type().Elem() {
+		l.defaults = deepCopy(target.Elem())
+	}
+	decoded := reflect.New(target.Type().Elem())
+	decoded.Elem().Set(deepCopy(l.defaults))
+	if err := v.Unmarshal(decoded.Interface()); err != nil {
+		return fmt.Errorf("failed to unmarshal configs: %w", err)
+	}
+	if err := Validate(decoded.Interface()); err != nil {
+		return err
+	}
+
+	target.Elem().Set(decoded.Elem())
+	l.origins = origins
+	return nil
+}
+
+// Origins returns the source that supplied each configuration key in the last
+// successful Load, e.g. {"server.port": "env:ORDER_SERVER_PORT"}.
+func (l *Loader) Origins() map[string]string {
+	origins := make(map[string]string, len(l.origins))
+	for k, v := range l.origins {
+		origins[k] = v
+	}
+	return origins
+}
+
+// Origin returns the source that supplied a configuration key in the last
+// successful Load, or an empty string if no source supplied it.
+func (l *Loader) Origin(key string) string {
+	return l.origins[key]
+}
+
+// Keys returns the configuration keys supplied in the last successful Load, sorted.
+func (l *Loader) Keys() []string {
+	keys := make([]string, 0, len(l.origins))
+	for k := range l.origins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// merge loads every source in order into a single viper instance and records
+// the origin of each key.
+func (l *Loader) merge(ctx context.Context, typ reflect.Type) (*viper.Viper, map[string]string, error) {
+	v := viper.New()
+	origins := make(map[string]string)
+
+	// known keys start with the fields of the target struct and grow with every source
+	known := make(map[string]bool)
+	if typ != nil {
+		walkFields(typ, "", func(key string, _ reflect.StructField) {
+			known[key] = true
+		})
+	}
+
+	apply := func(name string, values map[string]any, originOf func(key string) string) error {
+		if len(values) == 0 {
+			return nil
+		}
+		if err := v.MergeConfigMap(values); err != nil {
+			return fmt.Errorf("failed to merge configs from %s: %w", name, err)
+		}
+
+		leaves := make(map[string]any)
+		flatten(values, "", leaves)
+		for key := range leaves {
+			known[key] = true
+			origins[key] = originOf(key)
+		}
+		return nil
+	}
+
+	for _, source := range l.sources {
+		switch s := source.(type) {
+		case *defaultsSource:
+			s.typ = typ
+		case *envSource:
+			s.keys = sortedKeys(known)
+		case *flagSource:
+			s.keys = sortedKeys(known)
+		case *dirSource:
+			// each fragment is applied as its own file source
+			files, err := dirFiles(s.dir)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, file := range files {
+				fileSrc := FileSource(file, false)
+				values, err := fileSrc.Load(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+				if err := apply(fileSrc.Name(), values, constOrigin(fileSrc.Name())); err != nil {
+					return nil, nil, err
+				}
+			}
+			continue
+		}
+
+		values, err := source.Load(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		originOf := constOrigin(source.Name())
+		if env, ok := source.(*envSource); ok {
+			originOf = func(key string) string { return "env:" + env.vars[key] }
+		}
+		if err := apply(source.Name(), values, originOf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return v, origins, nil
+}
+
+// dirSource is a placeholder for a directory of fragments; the Loader expands
+// it into one file source per fragment so that origins name the fragment.
+type dirSource struct {
+	dir string
+}
+
+func (s *dirSource) Name() string {
+	return "dir:" + s.dir
+}
+
+func (s *dirSource) Load(ctx context.Context) (map[string]any, error) {
+	files, err := dirFiles(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	for _, file := range files {
+		values, err := FileSource(file, false).Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.MergeConfigMap(values); err != nil {
+			return nil, err
+		}
+	}
+	return v.AllSettings(), nil
+}
+
+func constOrigin(name string) func(string) string {
+	return func(string) string { return name }
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// watchPaths returns the files and fragment directories read by the Loader.
+func (l *Loader) watchPaths() (files, dirs []string) {
+	for _, source := range l.sources {
+		switch s := source.(type) {
+		case *fileSource:
+			files = append(files, s.path)
+		case *dirSource:
+			dirs = append(dirs, s.dir)
+		}
+	}
+	return files, dirs
+}
+
+// watchableSources returns the sources that detect their own changes.
+func (l *Loader) watchableSources() []WatchableSource {
+	var sources []WatchableSource
+	for _, source := range l.sources {
+		if s, ok := source.(WatchableSource); ok {
+			sources = append(sources, s)
+		}
+	}
+	return sources
+}
+
+// deepCopy returns a copy of v that shares no maps, slices or pointers with
+// it, so that decoding into the copy cannot modify v. Unexported fields are
+// copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	default:
+		return v
+	}
+}
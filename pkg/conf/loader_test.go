@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type loaderTestConfig struct {
+	Name    string            `mapstructure:"name"`
+	Port    int               `mapstructure:"port" validate:"gte=0,lte=65535"`
+	Brokers []string          `mapstructure:"brokers"`
+	Labels  map[string]string `mapstructure:"labels"`
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKeepsCallerDefaults(t *testing.T) {
+	path := writeConfig(t, "port: 8081\nlabels:\n  zone: b\n")
+	cfg := loaderTestConfig{
+		Name:    "order",
+		Port:    8080,
+		Brokers: []string{"localhost:9092"},
+		Labels:  map[string]string{"team": "orders"},
+	}
+
+	if err := NewLoader().File(path).Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Name != "order" || cfg.Port != 8081 {
+		t.Fatalf("Name, Port = %q, %d, want order, 8081", cfg.Name, cfg.Port)
+	}
+	if len(cfg.Brokers) != 1 || cfg.Brokers[0] != "localhost:9092" {
+		t.Fatalf("Brokers = %v, want the caller default", cfg.Brokers)
+	}
+	if cfg.Labels["team"] != "orders" || cfg.Labels["zone"] != "b" {
+		t.Fatalf("Labels = %v, want team: orders, zone: b", cfg.Labels)
+	}
+}
+
+func TestLoadFailureLeavesTargetUntouched(t *testing.T) {
+	path := writeConfig(t, "port: 70000\nbrokers: [a, b]\nlabels:\n  zone: b\n")
+	labels := map[string]string{"team": "orders"}
+	brokers := []string{"localhost:9092"}
+	cfg := loaderTestConfig{Port: 8080, Brokers: brokers, Labels: labels}
+
+	if err := NewLoader().File(path).Load(&cfg); err == nil {
+		t.Fatal("Load() error = nil, want a validation error")
+	}
+
+	if cfg.Port != 8080 || len(labels) != 1 || brokers[0] != "localhost:9092" {
+		t.Fatalf("failed Load modified the target: %+v", cfg)
+	}
+}
+
+func TestLoadReloadStartsFromCallerDefaults(t *testing.T) {
+	path := writeConfig(t, "name: payment\nlabels:\n  zone: b\n")
+	cfg := loaderTestConfig{Name: "order", Labels: map[string]string{"team": "orders"}}
+	loader := NewLoader().File(path)
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// 删除的配置项和 map 键在重新加载后恢复为调用方的默认值
+	if err := os.WriteFile(path, []byte("port: 8081\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := loaderTestConfig{Name: "order", Port: 8081, Labels: map[string]string{"team": "orders"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("reloaded configs = %+v, want %+v", cfg, want)
+	}
+}
@@ -0,0 +1,166 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryKV is an in-process KV for tests and local development.
+type MemoryKV struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	revs     map[string]uint64
+	revision uint64
+	// changed is closed and replaced on every change
+	changed chan struct{}
+}
+
+// NewMemoryKV creates an empty MemoryKV.
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{
+		values:  make(map[string][]byte),
+		revs:    make(map[string]uint64),
+		changed: make(chan struct{}),
+	}
+}
+
+// Name implements KV.
+func (m *MemoryKV) Name() string {
+	return "memory"
+}
+
+// Put sets the value of key.
+func (m *MemoryKV) Put(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revision++
+	m.values[key] = append([]byte(nil), value...)
+	m.revs[key] = m.revision
+	m.notify()
+}
+
+// Delete removes key.
+func (m *MemoryKV) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	m.revision++
+	delete(m.values, key)
+	m.revs[key] = m.revision
+	m.notify()
+}
+
+func (m *MemoryKV) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// Get implements KV.
+func (m *MemoryKV) Get(_ context.Context, key string) ([]byte, uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.values[key]
+	if !ok {
+		return nil, m.revs[key], fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return append([]byte(nil), value...), m.revs[key], nil
+}
+
+// Watch implements KV.
+func (m *MemoryKV) Watch(ctx context.Context, key string, revision uint64) (uint64, error) {
+	for {
+		m.mu.Lock()
+		current, changed := m.revs[key], m.changed
+		m.mu.Unlock()
+
+		if current != revision {
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return revision, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// FileKV is a KV backed by a directory, where key "order/config" is the file
+// dir/order/config. It stands in for a remote store in local development.
+// Changes are detected by polling the file's modification time.
+type FileKV struct {
+	dir      string
+	interval time.Duration
+}
+
+// NewFileKV creates a FileKV rooted at dir that polls for changes every interval.
+// A non-positive interval defaults to 1s.
+func NewFileKV(dir string, interval time.Duration) *FileKV {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &FileKV{dir: dir, interval: interval}
+}
+
+// Name implements KV.
+func (f *FileKV) Name() string {
+	return "file-kv"
+}
+
+// Get implements KV. The revision is the file's modification time.
+func (f *FileKV) Get(_ context.Context, key string) ([]byte, uint64, error) {
+	path := f.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, uint64(info.ModTime().UnixNano()), nil
+}
+
+// Watch implements KV.
+func (f *FileKV) Watch(ctx context.Context, key string, revision uint64) (uint64, error) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		var current uint64
+		if info, err := os.Stat(f.path(key)); err == nil {
+			current = uint64(info.ModTime().UnixNano())
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return revision, err
+		}
+		if current != revision {
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return revision, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *FileKV) path(key string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(strings.TrimLeft(key, "/")))
+}
@@ -1,62 +1,16 @@
-// Package conf provides configuration parsing functionality.
-// It supports parsing YAML configuration files and automatically binds environment variables.
-//
-// # Usage
-//
-// Basic usage:
-//
-//	type Config struct {
-//	  Server struct {
-//	    Host string `mapstructure:"host"`
-//	    Port int    `mapstructure:"port"`
-//	  } `mapstructure:"server"`
-//	  Database struct {
-//	    DSN string `mapstructure:"dsn"`
-//	  } `mapstructure:"database"`
-//	}
-//
-//	var cfg Config
-//	if err := conf.Parse("config.yaml", &cfg); err != nil {
-//	  log.Fatal(err)
-//	}
-//
-//	// Access parsed values
-//	fmt.Println("Server:", cfg.Server.Host, cfg.Server.Port)
-//	fmt.Println("Database:", cfg.Database.DSN)
-//
-// Environment Variables:
-// This package automatically binds environment variables. By default, it uses "GO_KIT" as
-// the environment variable prefix and replaces dots with underscores.
-//
-// For example, with the struct above:
-//   - GO_KIT_SERVER_HOST will bind to cfg.Server.Host
-//   - GO_KIT_DATABASE_DSN will bind to cfg.Database.DSN
-//
-// Configuration Reload:
-// The package supports automatic configuration reloading when the config file changes.
-// Pass reload functions as additional arguments to enable this feature:
-//
-//	func reloadCallback() {
-//	  // Handle configuration changes
-//	  fmt.Println("Configuration reloaded")
-//	}
-//
-//	if err := conf.Parse("config.yaml", &cfg, reloadCallback); err != nil {
-//	  log.Fatal(err)
-//	}
-//
-// In this case, whenever config.yaml is modified, reloadCallback will be executed.
+// Package conf loads configuration from layered sources into structs,
+// validates it and reloads it when files or remote sources change. See
+// README.md for sources, precedence, watching, validation and secrets.
 package conf
 
 import (
 	"fmt"
-	"strings"
 	"sync"
-
-	"github.com/fsnotify/fsnotify"
-	"github.com/spf13/viper"
 )
 
+// defaultEnvPrefix is the environment variable prefix used by Parse.
+const defaultEnvPrefix = "GO_KIT"
+
 var (
 	mu sync.RWMutex
 )
@@ -76,56 +30,103 @@ var (
 // The function automatically binds environment variables with the "GO_KIT" prefix.
 // Dots in configuration keys are replaced with underscores in environment variable names.
 // For example: server.host becomes GO_KIT_SERVER_HOST
+//
+// Parse is a shortcut for NewLoader().File(configFile).Env("GO_KIT"); use a
+// Loader directly for defaults, overlays, fragments, flags or another prefix.
+// Reload failures are logged with slog.Default(); use ParseWatch to handle
+// them or to query the reload status.
 func Parse(configFile string, obj any, reloads ...func()) error {
-	// 创建独立的viper实例，避免全局实例带来的冲突
-	v := viper.New()
-	v.SetConfigFile(configFile)
+	if len(reloads) == 0 {
+		mu.Lock()
+		defer mu.Unlock()
+		return NewLoader().File(configFile).Env(defaultEnvPrefix).Load(obj)
+	}
 
-	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read configs file %s: %w", configFile, err)
+	opts := make([]WatchOption, 0, len(reloads))
+	for _, reload := range reloads {
+		opts = append(opts, WithReload(reload))
 	}
+	_, err := ParseWatch(configFile, obj, opts...)
+	return err
+}
 
-	v.AutomaticEnv()
-	v.SetEnvPrefix("GO_KIT")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+// Reloader reloads a configuration object when its sources change.
+type Reloader struct {
+	loader  *Loader
+	obj     any
+	tracker *reloadTracker
+	watch   *loaderWatch
+}
+
+// ParseWatch parses the configuration file like Parse and reloads obj whenever
+// the file changes. Reload failures and reload callback panics are logged and
+// passed to the error handler set with WithErrorHandler; the previous
+// configuration is kept on failure, and every callback runs even if an
+// earlier one panics.
+//
+//	r, err := conf.ParseWatch("config.yaml", &cfg,
+//	  conf.WithReload(onReload),
+//	  conf.WithErrorHandler(func(err error) { alert(err) }),
+//	  conf.WithLogger(logger))
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	defer r.Close()
+//
+//	status := r.Status() // reload and failure counters, last error
+func ParseWatch(configFile string, obj any, opts ...WatchOption) (*Reloader, error) {
+	return LoadWatch(NewLoader().File(configFile).Env(defaultEnvPrefix), obj, opts...)
+}
+
+// LoadWatch loads obj from loader like Loader.Load and reloads it whenever a
+// file, fragment directory or remote source of the loader changes, e.g. a key
+// in etcd or Consul. It reports reloads like ParseWatch.
+// The loader must not be used elsewhere afterwards.
+func LoadWatch(loader *Loader, obj any, opts ...WatchOption) (*Reloader, error) {
+	r := &Reloader{
+		loader:  loader,
+		obj:     obj,
+		tracker: newReloadTracker(opts),
+	}
 
 	mu.Lock()
-	err := v.Unmarshal(obj)
+	err := loader.Load(obj)
 	mu.Unlock()
-
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal configs: %w", err)
+		return nil, err
 	}
 
-	if len(reloads) > 0 {
-		watchConfig(v, obj, reloads...)
+	lw, err := watchLoader(loader, r.reload)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	r.watch = lw
+	return r, nil
 }
 
-// watchConfig watches for configuration file changes and triggers reload callbacks.
-func watchConfig(v *viper.Viper, obj any, reloads ...func()) {
-	v.WatchConfig()
+// reload loads the configuration into obj and runs the reload callbacks.
+func (r *Reloader) reload() {
+	mu.Lock()
+	err := r.loader.Load(r.obj)
+	mu.Unlock()
 
-	v.OnConfigChange(func(_ fsnotify.Event) {
-		mu.Lock()
-		err := v.Unmarshal(obj)
-		mu.Unlock()
+	if err != nil {
+		r.tracker.failed(fmt.Errorf("conf: failed to reload configs: %w", err))
+		return
+	}
+	r.tracker.succeeded()
 
-		if err != nil {
-			_ = fmt.Errorf("conf.watchConfig: viper.Unmarshal error: %v", err)
-		} else {
-			// 将 defer/recover 移到循环外面，对所有 reload 函数提供统一的 panic 保护
-			defer func() {
-				if r := recover(); r != nil {
-					_ = fmt.Errorf("conf.watchConfig: reload function panic: %v", r)
-				}
-			}()
+	for i, reload := range r.tracker.opts.reloads {
+		_ = r.tracker.call(i, reload)
+	}
+}
+
+// Status returns the outcome of the reloads so far.
+func (r *Reloader) Status() ReloadStatus {
+	return r.tracker.Status()
+}
 
-			for _, reload := range reloads {
-				reload()
-			}
-		}
-	})
+// Close stops watching for changes.
+func (r *Reloader) Close() error {
+	return r.watch.Close()
 }
@@ -0,0 +1,147 @@
+package conf
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WatchOption configures how configuration reloads are reported.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	logger  *slog.Logger
+	onError func(error)
+	reloads []func()
+}
+
+// WithLogger sets the logger used to report reload failures and reload
+// callback panics. The default is slog.Default().
+func WithLogger(logger *slog.Logger) WatchOption {
+	return func(o *watchOptions) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithErrorHandler sets a function called with every reload failure and every
+// reload callback panic. It is called from the goroutine performing the reload.
+func WithErrorHandler(fn func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+// WithReload adds a function called after every successful reload.
+// It is used by ParseWatch and LoadWatch; Watcher uses Subscribe instead.
+func WithReload(fn func()) WatchOption {
+	return func(o *watchOptions) {
+		if fn != nil {
+			o.reloads = append(o.reloads, fn)
+		}
+	}
+}
+
+func newWatchOptions(opts []WatchOption) *watchOptions {
+	o := &watchOptions{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.logger = o.logger.With(slog.String("component", "conf"))
+	return o
+}
+
+// ReloadStatus describes the outcome of the configuration reloads so far.
+type ReloadStatus struct {
+	// Reloads is the number of successful reloads.
+	Reloads uint64
+	// Failures is the number of reloads that failed to load or validate;
+	// the previous configuration was kept each time.
+	Failures uint64
+	// CallbackPanics is the number of reload callbacks that panicked.
+	CallbackPanics uint64
+	// LastReload is the time of the last successful reload.
+	LastReload time.Time
+	// LastError is the most recent reload failure or callback panic.
+	LastError error
+	// LastErrorTime is the time of LastError.
+	LastErrorTime time.Time
+}
+
+// reloadTracker records reload outcomes and reports failures.
+type reloadTracker struct {
+	opts *watchOptions
+
+	mu     sync.Mutex
+	status ReloadStatus
+}
+
+func newReloadTracker(opts []WatchOption) *reloadTracker {
+	return &reloadTracker{opts: newWatchOptions(opts)}
+}
+
+// Status returns the current reload status.
+func (t *reloadTracker) Status() ReloadStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// succeeded records a successful reload.
+func (t *reloadTracker) succeeded() {
+	t.mu.Lock()
+	t.status.Reloads++
+	t.status.LastReload = time.Now()
+	t.mu.Unlock()
+}
+
+// failed records a failed reload and reports err.
+func (t *reloadTracker) failed(err error) {
+	t.mu.Lock()
+	t.status.Failures++
+	t.status.LastError = err
+	t.status.LastErrorTime = time.Now()
+	t.mu.Unlock()
+
+	t.opts.logger.Error("Failed to reload configs, keeping the current configuration", slog.Any("error", err))
+	t.notify(err)
+}
+
+// call runs a reload callback, recovering and reporting a panic so that the
+// remaining callbacks still run. It returns the panic as an error.
+func (t *reloadTracker) call(index int, fn func()) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err = fmt.Errorf("conf: reload callback %d panicked: %v", index, r)
+
+		t.mu.Lock()
+		t.status.CallbackPanics++
+		t.status.LastError = err
+		t.status.LastErrorTime = time.Now()
+		t.mu.Unlock()
+
+		t.opts.logger.Error("Configs reload callback panicked", slog.Int("callback", index), slog.Any("error", r))
+		t.notify(err)
+	}()
+
+	fn()
+	return nil
+}
+
+// notify passes err to the error handler, if any.
+func (t *reloadTracker) notify(err error) {
+	if t.opts.onError == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.opts.logger.Error("Configs reload error handler panicked", slog.Any("error", r))
+		}
+	}()
+	t.opts.onError(err)
+}
@@ -0,0 +1,92 @@
+package conf
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWatchReportsReloadErrors(t *testing.T) {
+	path := writeConfig(t, "port: 8080\n")
+	errs := make(chan error, 1)
+	reloaded := make(chan int, 1)
+	var cfg loaderTestConfig
+
+	r, err := ParseWatch(path, &cfg,
+		WithReload(func() { reloaded <- cfg.Port }),
+		WithErrorHandler(func(err error) { errs <- err }),
+		WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatalf("ParseWatch() error = %v", err)
+	}
+	defer r.Close()
+
+	// 校验失败的重新加载保留原有配置并报告错误
+	if err := os.WriteFile(path, []byte("port: 70000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "failed to reload configs") {
+			t.Fatalf("reported error = %v, want a reload failure", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reload failure was not reported")
+	}
+	status := r.Status()
+	if status.Failures != 1 || status.Reloads != 0 || status.LastError == nil || status.LastErrorTime.IsZero() {
+		t.Fatalf("Status() = %+v, want one failure", status)
+	}
+	if cfg.Port != 8080 {
+		t.Fatalf("Port = %d after a failed reload, want 8080", cfg.Port)
+	}
+
+	if err := os.WriteFile(path, []byte("port: 8081\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case port := <-reloaded:
+		if port != 8081 {
+			t.Fatalf("reloaded Port = %d, want 8081", port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("configs were not reloaded")
+	}
+	if status := r.Status(); status.Reloads != 1 || status.LastReload.IsZero() {
+		t.Fatalf("Status() = %+v, want one reload", status)
+	}
+}
+
+func TestReloadTrackerCallbackPanics(t *testing.T) {
+	var reported []error
+	tracker := newReloadTracker([]WatchOption{
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithErrorHandler(func(err error) {
+			reported = append(reported, err)
+			panic("error handler")
+		}),
+	})
+
+	ran := false
+	if err := tracker.call(0, func() { panic("boom") }); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("call() error = %v, want the panic", err)
+	}
+	// 前一个回调 panic 或错误处理函数 panic 都不影响后续回调
+	if err := tracker.call(1, func() { ran = true }); err != nil || !ran {
+		t.Fatalf("call() = %v, ran = %v, want the next callback to run", err, ran)
+	}
+
+	failure := errors.New("invalid configs")
+	tracker.failed(failure)
+
+	status := tracker.Status()
+	if status.CallbackPanics != 1 || status.Failures != 1 || !errors.Is(status.LastError, failure) {
+		t.Fatalf("Status() = %+v, want one panic and one failure", status)
+	}
+	if len(reported) != 2 || !errors.Is(reported[1], failure) {
+		t.Fatalf("reported errors = %v, want the panic and the failure", reported)
+	}
+}
@@ -0,0 +1,319 @@
+package conf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ErrKeyNotFound is returned by a KV when the key does not exist.
+var ErrKeyNotFound = errors.New("conf: key not found")
+
+// KV is a key-value store holding configuration documents, such as etcd or Consul.
+type KV interface {
+	// Name identifies the store in origin reports, e.g. "etcd".
+	Name() string
+	// Get returns the value of key and its revision. It returns ErrKeyNotFound
+	// if the key does not exist.
+	Get(ctx context.Context, key string) (value []byte, revision uint64, err error)
+	// Watch blocks until the revision of key differs from revision, or ctx is
+	// done, and returns the new revision. It may return the same revision
+	// when the store ends a long poll without a change.
+	Watch(ctx context.Context, key string, revision uint64) (uint64, error)
+}
+
+// WatchableSource is a Source that detects its own changes, such as a
+// key-value store. Watcher and Reloader reload when onChange is called.
+type WatchableSource interface {
+	Source
+	// Watch calls onChange after every change until ctx is done.
+	Watch(ctx context.Context, onChange func())
+}
+
+// RemoteOption configures a remote source.
+type RemoteOption func(*remoteSource)
+
+// WithFormat sets the format of the configuration document stored in the key,
+// e.g. "yaml" or "json". The default is "yaml".
+func WithFormat(format string) RemoteOption {
+	return func(s *remoteSource) {
+		s.format = format
+	}
+}
+
+// WithCacheFile caches the last value read from the store in path. The cache
+// is used when the store is unavailable at startup.
+func WithCacheFile(path string) RemoteOption {
+	return func(s *remoteSource) {
+		s.cacheFile = path
+	}
+}
+
+// WithFallbackFile sets a local configuration file used when the store is
+// unavailable at startup and no cache exists.
+func WithFallbackFile(path string) RemoteOption {
+	return func(s *remoteSource) {
+		s.fallbackFile = path
+	}
+}
+
+// WithRemoteTimeout sets the timeout of a single read from the store.
+// The default is 5s.
+func WithRemoteTimeout(timeout time.Duration) RemoteOption {
+	return func(s *remoteSource) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// WithRemoteLogger sets the logger used to report fallbacks and watch
+// errors. The default is slog.Default().
+func WithRemoteLogger(logger *slog.Logger) RemoteOption {
+	return func(s *remoteSource) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// KVOption configures an etcd or Consul client.
+type KVOption func(*kvOptions)
+
+type kvOptions struct {
+	client *http.Client
+	token  string
+}
+
+// WithHTTPClient sets the HTTP client used to reach the store.
+// The client must not have a timeout shorter than a watch long poll;
+// requests are bounded by their context instead.
+func WithHTTPClient(client *http.Client) KVOption {
+	return func(o *kvOptions) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// WithToken sets the access token: the etcd auth token sent in the
+// Authorization header, or the Consul ACL token.
+func WithToken(token string) KVOption {
+	return func(o *kvOptions) {
+		o.token = token
+	}
+}
+
+func newKVOptions(opts []KVOption) kvOptions {
+	o := kvOptions{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// remoteSource reads a configuration document from a key of a KV.
+type remoteSource struct {
+	kv           KV
+	key          string
+	format       string
+	cacheFile    string
+	fallbackFile string
+	timeout      time.Duration
+	logger       *slog.Logger
+
+	mu       sync.Mutex
+	revision uint64
+	// remote reports whether the last Load read the store rather than a local copy
+	remote bool
+}
+
+// RemoteSource returns a Source that reads the configuration document stored
+// in key. When the store is unavailable the source falls back to the cache
+// file, then to the fallback file; Watch keeps retrying the store and
+// triggers a reload once it is reachable again.
+func RemoteSource(kv KV, key string, opts ...RemoteOption) WatchableSource {
+	s := &remoteSource{
+		kv:      kv,
+		key:     key,
+		format:  "yaml",
+		timeout: 5 * time.Second,
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.logger = s.logger.With(slog.String("component", "conf"), slog.String("source", s.Name()))
+	return s
+}
+
+func (s *remoteSource) Name() string {
+	return s.kv.Name() + ":" + s.key
+}
+
+func (s *remoteSource) Load(ctx context.Context) (map[string]any, error) {
+	getCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	value, revision, err := s.kv.Get(getCtx, s.key)
+	cancel()
+
+	if err == nil {
+		values, err := s.decode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.revision = revision
+		s.remote = true
+		s.mu.Unlock()
+
+		s.writeCache(value)
+		return values, nil
+	}
+
+	// 已经从远端加载过配置时，热重载失败应保留当前配置，而不是回退到本地副本
+	s.mu.Lock()
+	loaded := s.remote
+	s.mu.Unlock()
+	if loaded {
+		return nil, fmt.Errorf("failed to read configs from %s: %w", s.Name(), err)
+	}
+
+	return s.loadLocal(err)
+}
+
+// loadLocal loads the cache or fallback file after the store failed with cause.
+func (s *remoteSource) loadLocal(cause error) (map[string]any, error) {
+	if s.cacheFile != "" {
+		if value, err := os.ReadFile(s.cacheFile); err == nil {
+			s.logger.Warn("Configs store unavailable, using cached configs",
+				slog.String("cache", s.cacheFile), slog.Any("error", cause))
+			return s.decode(value)
+		}
+	}
+
+	if s.fallbackFile != "" {
+		s.logger.Warn("Configs store unavailable, using fallback configs file",
+			slog.String("file", s.fallbackFile), slog.Any("error", cause))
+		return FileSource(s.fallbackFile, false).Load(context.Background())
+	}
+
+	return nil, fmt.Errorf("failed to read configs from %s: %w", s.Name(), cause)
+}
+
+// decode parses a configuration document in the source's format.
+func (s *remoteSource) decode(value []byte) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigType(s.format)
+	if err := v.ReadConfig(bytes.NewReader(value)); err != nil {
+		return nil, fmt.Errorf("failed to parse configs from %s: %w", s.Name(), err)
+	}
+	return v.AllSettings(), nil
+}
+
+// writeCache atomically replaces the cache file with value.
+func (s *remoteSource) writeCache(value []byte) {
+	if s.cacheFile == "" {
+		return
+	}
+
+	err := func() error {
+		if err := os.MkdirAll(filepath.Dir(s.cacheFile), 0o755); err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(filepath.Dir(s.cacheFile), filepath.Base(s.cacheFile)+".*")
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }()
+
+		if _, err := tmp.Write(value); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), s.cacheFile)
+	}()
+	if err != nil {
+		s.logger.Warn("Failed to write configs cache", slog.String("cache", s.cacheFile), slog.Any("error", err))
+	}
+}
+
+func (s *remoteSource) Watch(ctx context.Context, onChange func()) {
+	const (
+		minBackoff = time.Second
+		maxBackoff = 30 * time.Second
+	)
+	backoff := minBackoff
+
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		revision, remote := s.revision, s.remote
+		s.mu.Unlock()
+
+		var err error
+		if !remote {
+			// 启动时使用了本地副本，远端恢复后立即重新加载
+			getCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			_, _, err = s.kv.Get(getCtx, s.key)
+			cancel()
+			if err == nil {
+				s.logger.Info("Configs store available again, reloading")
+				onChange()
+				// 重新加载失败时保持 remote=false，继续按退避间隔重试
+				s.mu.Lock()
+				remote = s.remote
+				s.mu.Unlock()
+				if remote {
+					backoff = minBackoff
+					continue
+				}
+			}
+		} else {
+			var next uint64
+			next, err = s.kv.Watch(ctx, s.key, revision)
+			if err == nil {
+				if next == revision {
+					backoff = minBackoff
+					continue
+				}
+				// Load advances the revision once it has read the new value;
+				// if the reload failed, the change is retried after the backoff
+				onChange()
+				s.mu.Lock()
+				reloaded := s.revision != revision
+				s.mu.Unlock()
+				if reloaded {
+					backoff = minBackoff
+					continue
+				}
+				err = fmt.Errorf("failed to reload revision %d", next)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Warn("Failed to watch configs store", slog.Any("error", err), slog.Duration("retry", backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
@@ -0,0 +1,223 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errStoreDown = errors.New("store down")
+
+// flakyKV is a MemoryKV whose reads fail while down is set
+type flakyKV struct {
+	*MemoryKV
+	down atomic.Bool
+}
+
+func (k *flakyKV) Get(ctx context.Context, key string) ([]byte, uint64, error) {
+	if k.down.Load() {
+		return nil, 0, errStoreDown
+	}
+	return k.MemoryKV.Get(ctx, key)
+}
+
+func newTestRemoteSource(kv KV, opts ...RemoteOption) WatchableSource {
+	opts = append(opts, WithRemoteLogger(slog.New(slog.DiscardHandler)))
+	return RemoteSource(kv, "order/config", opts...)
+}
+
+func TestRemoteSourceLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		down     bool
+		cache    string
+		fallback string
+		want     string
+		wantErr  bool
+	}{
+		{name: "store", cache: "name: cached\n", fallback: "name: fallback\n", want: "remote"},
+		{name: "cache file", down: true, cache: "name: cached\n", fallback: "name: fallback\n", want: "cached"},
+		{name: "fallback file", down: true, fallback: "name: fallback\n", want: "fallback"},
+		{name: "unavailable", down: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kv := &flakyKV{MemoryKV: NewMemoryKV()}
+			kv.Put("order/config", []byte("name: remote\n"))
+			kv.down.Store(tt.down)
+
+			cacheFile := filepath.Join(t.TempDir(), "cache", "config.yaml")
+			opts := []RemoteOption{WithCacheFile(cacheFile)}
+			if tt.cache != "" {
+				if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(cacheFile, []byte(tt.cache), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.fallback != "" {
+				opts = append(opts, WithFallbackFile(writeConfig(t, tt.fallback)))
+			}
+
+			values, err := newTestRemoteSource(kv, opts...).Load(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, errStoreDown) {
+					t.Fatalf("Load() error = %v, want %v", err, errStoreDown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if values["name"] != tt.want {
+				t.Fatalf("name = %v, want %s", values["name"], tt.want)
+			}
+
+			if !tt.down {
+				cached, err := os.ReadFile(cacheFile)
+				if err != nil || string(cached) != "name: remote\n" {
+					t.Fatalf("cache file = %q, %v, want the value read from the store", cached, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoteSourceReloadKeepsCurrentConfigs(t *testing.T) {
+	kv := &flakyKV{MemoryKV: NewMemoryKV()}
+	kv.Put("order/config", []byte("name: remote\n"))
+	src := newTestRemoteSource(kv, WithFallbackFile(writeConfig(t, "name: fallback\n")))
+
+	if _, err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// 从远端加载过之后，远端不可用时返回错误而不是回退到本地文件
+	kv.down.Store(true)
+	if _, err := src.Load(context.Background()); !errors.Is(err, errStoreDown) {
+		t.Fatalf("Load() error = %v, want %v", err, errStoreDown)
+	}
+}
+
+func watchRemote(t *testing.T, src WatchableSource, onChange func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		src.Watch(ctx, onChange)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestRemoteSourceWatchRecovered(t *testing.T) {
+	kv := &flakyKV{MemoryKV: NewMemoryKV()}
+	kv.Put("order/config", []byte("name: remote\n"))
+	kv.down.Store(true)
+	src := newTestRemoteSource(kv, WithFallbackFile(writeConfig(t, "name: fallback\n")))
+
+	if values, err := src.Load(context.Background()); err != nil || values["name"] != "fallback" {
+		t.Fatalf("Load() = %v, %v, want the fallback file", values, err)
+	}
+
+	reloaded := make(chan any, 1)
+	watchRemote(t, src, func() {
+		if values, err := src.Load(context.Background()); err == nil {
+			reloaded <- values["name"]
+		}
+	})
+	kv.down.Store(false)
+
+	select {
+	case name := <-reloaded:
+		if name != "remote" {
+			t.Fatalf("reloaded name = %v, want remote", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("store recovered but the configs were not reloaded")
+	}
+}
+
+func TestRemoteSourceWatchRetriesFailedReload(t *testing.T) {
+	kv := &flakyKV{MemoryKV: NewMemoryKV()}
+	kv.Put("order/config", []byte("name: v1\n"))
+	src := newTestRemoteSource(kv)
+	if _, err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	reloaded := make(chan any, 1)
+	watchRemote(t, src, func() {
+		values, err := src.Load(context.Background())
+		if err != nil {
+			// 第一次重新加载失败后恢复远端，同一个变更应被再次加载
+			kv.down.Store(false)
+			return
+		}
+		reloaded <- values["name"]
+	})
+	kv.down.Store(true)
+	kv.Put("order/config", []byte("name: v2\n"))
+
+	select {
+	case name := <-reloaded:
+		if name != "v2" {
+			t.Fatalf("reloaded name = %v, want v2", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was lost after a failed reload")
+	}
+}
+
+func TestMemoryKVWatch(t *testing.T) {
+	kv := NewMemoryKV()
+	kv.Put("a", []byte("1"))
+	_, revision, err := kv.Get(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if next, err := kv.Watch(context.Background(), "a", 0); err != nil || next != revision {
+		t.Fatalf("Watch() = %d, %v, want %d at once", next, err, revision)
+	}
+
+	result := make(chan uint64, 1)
+	go func() {
+		next, _ := kv.Watch(context.Background(), "a", revision)
+		result <- next
+	}()
+
+	// 其他 key 的变更不会唤醒 Watch
+	kv.Put("b", []byte("1"))
+	select {
+	case next := <-result:
+		t.Fatalf("Watch() returned %d after another key changed", next)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	kv.Delete("a")
+	select {
+	case next := <-result:
+		if next <= revision {
+			t.Fatalf("Watch() = %d, want a revision after %d", next, revision)
+		}
+		revision = next
+	case <-time.After(time.Second):
+		t.Fatal("Watch() did not return after the key was deleted")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := kv.Watch(ctx, "a", revision); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Watch() error = %v, want %v", err, context.Canceled)
+	}
+}
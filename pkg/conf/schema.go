@@ -0,0 +1,383 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldDoc describes a configuration key for documentation.
+type FieldDoc struct {
+	// Key is the dotted key as written in configuration files; elements of
+	// lists are written as "mysql[].addr".
+	Key string
+	// Type is the value type, e.g. "string", "integer", "duration" or "[]string".
+	Type string
+	// Default is the value of the `default` tag.
+	Default string
+	// Env is the environment variable bound to the key, or empty for maps and
+	// keys inside lists, which cannot be set from the environment.
+	Env string
+	// Description is the value of the `desc` tag.
+	Description string
+	// Required reports whether the `validate` tag contains "required".
+	Required bool
+	// Enum lists the allowed values of a `validate:"oneof=..."` rule.
+	Enum []string
+}
+
+// Describe returns the documentation of every configuration key of obj, a
+// struct or pointer to struct, in field order. envPrefix is the prefix used
+// by Parse or Loader.Env, e.g. "GO_KIT".
+//
+// Descriptions come from `desc:"..."` tags and defaults from `default:"..."` tags:
+//
+//	type Server struct {
+//	  Port int `mapstructure:"port" default:"8080" desc:"HTTP listen port" validate:"required"`
+//	}
+func Describe(obj any, envPrefix string) []FieldDoc {
+	var docs []FieldDoc
+	describeStruct(indirectType(reflect.TypeOf(obj)), "", envPrefix, true, &docs)
+	return docs
+}
+
+func describeStruct(t reflect.Type, prefix, envPrefix string, bindable bool, docs *[]FieldDoc) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || skipSchemaField(field.Type) {
+			continue
+		}
+		name, squash, ok := schemaFieldName(field)
+		if !ok {
+			continue
+		}
+
+		ft := indirectType(field.Type)
+		if squash && ft.Kind() == reflect.Struct {
+			describeStruct(ft, prefix, envPrefix, bindable, docs)
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		switch {
+		case ft.Kind() == reflect.Struct && !isLeafStruct(ft) && !isSecret(ft):
+			describeStruct(ft, key, envPrefix, bindable, docs)
+			continue
+		case ft.Kind() == reflect.Slice && isNestedStruct(ft.Elem()):
+			describeStruct(indirectType(ft.Elem()), key+"[]", envPrefix, false, docs)
+			continue
+		case ft.Kind() == reflect.Map && isNestedStruct(ft.Elem()):
+			describeStruct(indirectType(ft.Elem()), key+".<name>", envPrefix, false, docs)
+			continue
+		}
+
+		doc := FieldDoc{
+			Key:         key,
+			Type:        typeName(ft),
+			Default:     field.Tag.Get("default"),
+			Description: field.Tag.Get("desc"),
+		}
+		if bindable && ft.Kind() != reflect.Map {
+			doc.Env = EnvName(envPrefix, key)
+		}
+		doc.Required, doc.Enum, _, _ = validateRules(field)
+		*docs = append(*docs, doc)
+	}
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the
+// configuration file format of obj, a struct or pointer to struct. It can be
+// referenced from YAML files for editor validation, e.g. with a
+// "# yaml-language-server: $schema=config.schema.json" comment.
+func JSONSchema(obj any, title string) ([]byte, error) {
+	schema := structSchema(indirectType(reflect.TypeOf(obj)))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	if title != "" {
+		schema["title"] = title
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	collectProperties(t, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func collectProperties(t reflect.Type, properties map[string]any, required *[]string) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || skipSchemaField(field.Type) {
+			continue
+		}
+		name, squash, ok := schemaFieldName(field)
+		if !ok {
+			continue
+		}
+
+		ft := indirectType(field.Type)
+		if squash && ft.Kind() == reflect.Struct {
+			collectProperties(ft, properties, required)
+			continue
+		}
+
+		schema := typeSchema(ft)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			schema["description"] = desc
+		}
+		if def := field.Tag.Get("default"); def != "" {
+			schema["default"] = defaultValue(ft, def)
+		}
+
+		isRequired, enum, minimum, maximum := validateRules(field)
+		if isRequired {
+			*required = append(*required, name)
+		}
+		if len(enum) > 0 {
+			schema["enum"] = enum
+		}
+		if minimum != nil && isNumeric(ft) {
+			schema["minimum"] = *minimum
+		}
+		if minimum != nil && ft.Kind() == reflect.Slice {
+			schema["minItems"] = int(*minimum)
+		}
+		if maximum != nil && isNumeric(ft) {
+			schema["maximum"] = *maximum
+		}
+		properties[name] = schema
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	t = indirectType(t)
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return map[string]any{
+			"type":        "string",
+			"pattern":     `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+			"description": "duration, e.g. 100ms, 30s, 5m",
+		}
+	case t == reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case isSecret(t):
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Struct && isLeafStruct(t):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// typeName returns a short type name for documentation.
+func typeName(t reflect.Type) string {
+	t = indirectType(t)
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return "duration"
+	case t == reflect.TypeFor[time.Time]():
+		return "time"
+	case isSecret(t):
+		return "secret"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[string]" + typeName(t.Elem())
+	default:
+		return "string"
+	}
+}
+
+// Markdown renders docs as a Markdown table.
+func Markdown(docs []FieldDoc) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Required | Env | Description |\n")
+	buf.WriteString("|-----|------|---------|----------|-----|-------------|\n")
+	for _, doc := range docs {
+		description := doc.Description
+		if len(doc.Enum) > 0 {
+			if description != "" && !strings.HasSuffix(description, ".") {
+				description += "."
+			}
+			if description != "" {
+				description += " "
+			}
+			description += "One of: `" + strings.Join(doc.Enum, "`, `") + "`."
+		}
+
+		required := ""
+		if doc.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&buf, "| `%s` | %s | %s | %s | %s | %s |\n",
+			doc.Key, doc.Type, code(doc.Default), required, code(doc.Env), escapeCell(description))
+	}
+	return buf.Bytes()
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// schemaFieldName returns the key of a field as written in configuration
+// files. Unlike fieldKey it keeps the case of the mapstructure tag, since
+// YAML editors validate keys case-sensitively.
+func schemaFieldName(field reflect.StructField) (name string, squash, ok bool) {
+	tag := field.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false, false
+	}
+	squash = strings.Contains(opts, "squash") || (field.Anonymous && name == "")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, squash, true
+}
+
+// validateRules extracts the documented rules of a `validate` tag.
+func validateRules(field reflect.StructField) (required bool, enum []string, minimum, maximum *float64) {
+	tag := field.Tag.Get("validate")
+	// rules after "dive" apply to elements
+	tag, _, _ = strings.Cut(tag, ",dive")
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			enum = strings.Fields(param)
+		case "gte", "min":
+			if v, err := strconv.ParseFloat(param, 64); err == nil {
+				minimum = &v
+			}
+		case "lte", "max":
+			if v, err := strconv.ParseFloat(param, 64); err == nil {
+				maximum = &v
+			}
+		}
+	}
+	return required, enum, minimum, maximum
+}
+
+func defaultValue(t reflect.Type, def string) any {
+	switch indirectType(t).Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(def); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t != reflect.TypeFor[time.Duration]() {
+			if v, err := strconv.ParseInt(def, 10, 64); err == nil {
+				return v
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(def, 64); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// skipSchemaField reports whether a field cannot be set from configuration
+// files, such as loggers, clients and functions.
+func skipSchemaField(t reflect.Type) bool {
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	t = indirectType(t)
+	return t.Kind() == reflect.Struct && !isLeafStruct(t)
+}
+
+func isNumeric(t reflect.Type) bool {
+	switch indirectType(t).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t != reflect.TypeFor[time.Duration]()
+	}
+	return false
+}
+
+func isSecret(t reflect.Type) bool {
+	return t == reflect.TypeFor[Secret]()
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,108 @@
+package conf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaTestBase struct {
+	Name string `mapstructure:"name" validate:"required" desc:"Service name"`
+}
+
+type schemaTestConfig struct {
+	Base   schemaTestBase `mapstructure:",squash"`
+	Server struct {
+		Port    int           `mapstructure:"port" default:"8080" validate:"gte=1,lte=65535" desc:"Listen port"`
+		Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+		Mode    string        `mapstructure:"mode" default:"debug" validate:"oneof=debug release" desc:"Run mode"`
+	} `mapstructure:"server"`
+	Password Secret              `mapstructure:"password"`
+	Brokers  []string            `mapstructure:"brokers" validate:"min=1"`
+	Labels   map[string]string   `mapstructure:"labels"`
+	Replicas []schemaTestReplica `mapstructure:"replicas"`
+	OnReload func()              `mapstructure:"on_reload"`
+	Ignored  string              `mapstructure:"-"`
+}
+
+type schemaTestReplica struct {
+	Addr string `mapstructure:"addr" validate:"required"`
+}
+
+func TestDescribe(t *testing.T) {
+	want := []FieldDoc{
+		{Key: "name", Type: "string", Env: "APP_NAME", Description: "Service name", Required: true},
+		{Key: "server.port", Type: "integer", Default: "8080", Env: "APP_SERVER_PORT", Description: "Listen port"},
+		{Key: "server.timeout", Type: "duration", Default: "5s", Env: "APP_SERVER_TIMEOUT"},
+		{Key: "server.mode", Type: "string", Default: "debug", Env: "APP_SERVER_MODE", Description: "Run mode", Enum: []string{"debug", "release"}},
+		{Key: "password", Type: "secret", Env: "APP_PASSWORD"},
+		{Key: "brokers", Type: "[]string", Env: "APP_BROKERS"},
+		{Key: "labels", Type: "map[string]string"},
+		{Key: "replicas[].addr", Type: "string", Required: true},
+	}
+
+	got := Describe(&schemaTestConfig{}, "APP")
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Describe() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	markdown := string(Markdown(got))
+	for _, row := range []string{
+		"| `server.port` | integer | `8080` |  | `APP_SERVER_PORT` | Listen port |",
+		"| `server.mode` | string | `debug` |  | `APP_SERVER_MODE` | Run mode. One of: `debug`, `release`. |",
+		"| `replicas[].addr` | string |  | yes |  |  |",
+	} {
+		if !strings.Contains(markdown, row) {
+			t.Fatalf("Markdown() =\n%s\nwant row %s", markdown, row)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema(&schemaTestConfig{}, "test")
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("JSONSchema() is not JSON: %v", err)
+	}
+	if schema["title"] != "test" || !reflect.DeepEqual(schema["required"], []any{"name"}) {
+		t.Fatalf("title, required = %v, %v, want test, [name]", schema["title"], schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]any)
+	if _, ok := properties["on_reload"]; ok {
+		t.Fatal("schema documents a field that cannot be configured")
+	}
+	server := properties["server"].(map[string]any)["properties"].(map[string]any)
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "port", got: server["port"], want: map[string]any{
+			"type": "integer", "default": float64(8080), "minimum": float64(1), "maximum": float64(65535), "description": "Listen port",
+		}},
+		{name: "mode enum", got: server["mode"].(map[string]any)["enum"], want: []any{"debug", "release"}},
+		{name: "timeout default", got: server["timeout"].(map[string]any)["default"], want: "5s"},
+		{name: "password", got: properties["password"], want: map[string]any{"type": "string"}},
+		{name: "brokers", got: properties["brokers"], want: map[string]any{
+			"type": "array", "items": map[string]any{"type": "string"}, "minItems": float64(1),
+		}},
+		{name: "labels", got: properties["labels"], want: map[string]any{
+			"type": "object", "additionalProperties": map[string]any{"type": "string"},
+		}},
+		{name: "replicas required", got: properties["replicas"].(map[string]any)["items"].(map[string]any)["required"], want: []any{"addr"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Fatalf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
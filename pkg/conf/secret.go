@@ -0,0 +1,223 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// SecretProvider resolves secret references of one scheme, e.g. the reference
+// "secret/data/db#password" in ${vault:secret/data/db#password}.
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to a SecretProvider.
+type SecretProviderFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretMu        sync.RWMutex
+	secretProviders = map[string]SecretProvider{
+		"env":  SecretProviderFunc(resolveEnvSecret),
+		"file": SecretProviderFunc(resolveFileSecret),
+	}
+
+	// secretRef matches ${scheme:ref}; the scheme is lowercase letters, digits, '_' or '-'
+	secretRef = regexp.MustCompile(`\$\{([a-z][a-z0-9_-]*):([^}]*)\}`)
+)
+
+// RegisterSecretProvider registers the provider for references of the form
+// ${scheme:ref}, replacing any provider registered for the scheme.
+// The schemes "env" and "file" are registered by default.
+// It should be called before configuration is parsed.
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretProviders[scheme] = provider
+}
+
+func secretProvider(scheme string) (SecretProvider, bool) {
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	provider, ok := secretProviders[scheme]
+	return provider, ok
+}
+
+// resolveEnvSecret resolves ${env:NAME} to the value of the environment variable NAME.
+func resolveEnvSecret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// resolveFileSecret resolves ${file:path} to the content of the file, without
+// trailing newlines, as written by Docker and Kubernetes secrets.
+func resolveFileSecret(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveSecrets replaces secret references in every string value of v,
+// including the strings nested in lists and in maps inside lists, such as
+// mysql[0].password.
+func resolveSecrets(ctx context.Context, v *viper.Viper) error {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		value := v.Get(key)
+		resolved, changed := resolveValue(ctx, key, value, &errs)
+		if changed {
+			v.Set(key, resolved)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to resolve configs secrets: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// resolveValue replaces secret references in value, walking nested lists and
+// maps. It returns a copy when a reference was replaced, so that the maps and
+// lists of the sources are not modified. Errors are appended to errs with the
+// path of the value.
+func resolveValue(ctx context.Context, path string, value any, errs *[]error) (any, bool) {
+	switch value := value.(type) {
+	case string:
+		resolved, err := resolveString(ctx, value)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+			return value, false
+		}
+		return resolved, resolved != value
+	case []any:
+		var items []any
+		for i, item := range value {
+			resolved, changed := resolveValue(ctx, fmt.Sprintf("%s[%d]", path, i), item, errs)
+			if !changed {
+				continue
+			}
+			if items == nil {
+				items = append([]any(nil), value...)
+			}
+			items[i] = resolved
+		}
+		if items == nil {
+			return value, false
+		}
+		return items, true
+	case map[string]any:
+		var m map[string]any
+		for k, item := range value {
+			resolved, changed := resolveValue(ctx, path+"."+k, item, errs)
+			if !changed {
+				continue
+			}
+			if m == nil {
+				m = make(map[string]any, len(value))
+				for k, item := range value {
+					m[k] = item
+				}
+			}
+			m[k] = resolved
+		}
+		if m == nil {
+			return value, false
+		}
+		return m, true
+	default:
+		return value, false
+	}
+}
+
+// resolveString replaces every ${scheme:ref} in s. Errors never include the
+// resolved values.
+func resolveString(ctx context.Context, s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var firstErr error
+	resolved := secretRef.ReplaceAllStringFunc(s, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		parts := secretRef.FindStringSubmatch(match)
+		scheme, ref := parts[1], parts[2]
+
+		provider, ok := secretProvider(scheme)
+		if !ok {
+			firstErr = fmt.Errorf("unknown secret provider %q", scheme)
+			return match
+		}
+		value, err := provider.Resolve(ctx, ref)
+		if err != nil {
+			firstErr = fmt.Errorf("failed to resolve ${%s:%s}: %w", scheme, ref, err)
+			return match
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return resolved, nil
+}
+
+// redacted replaces secret values in output.
+const redacted = "******"
+
+// Secret is a string that is redacted when formatted, logged or marshaled,
+// for configuration values such as passwords and tokens:
+//
+//	type MySQL struct {
+//	  Password conf.Secret `mapstructure:"password"`
+//	}
+//
+//	fmt.Println(cfg.Password)      // ******
+//	db.Connect(cfg.Password.Value()) // the real value
+type Secret string
+
+// Value returns the secret in plain text.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer and hides the secret for %s, %v and %q.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString implements fmt.GoStringer and hides the secret for %#v.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("conf.Secret(%q)", s.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalText implements encoding.TextMarshaler, so JSON, YAML and text
+// encoders output the redacted form.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,67 @@
+package conf
+
+import (
+	"strings"
+	"testing"
+)
+
+type secretTestConfig struct {
+	Password string `mapstructure:"password"`
+	MySQL    []struct {
+		Instance string `mapstructure:"instance"`
+		Password Secret `mapstructure:"password"`
+	} `mapstructure:"mysql"`
+	Asynq []struct {
+		Instance      string `mapstructure:"instance"`
+		RedisPassword string `mapstructure:"redis_password"`
+		Sentinel      struct {
+			Password string `mapstructure:"password"`
+		} `mapstructure:"sentinel"`
+	} `mapstructure:"asynq"`
+}
+
+func TestResolveSecretsInListEntries(t *testing.T) {
+	t.Setenv("CONF_TEST_MYSQL_PASSWORD", "mysql-secret")
+	t.Setenv("CONF_TEST_REDIS_PASSWORD", "redis-secret")
+	path := writeConfig(t, `
+password: "${env:CONF_TEST_REDIS_PASSWORD}"
+mysql:
+  - instance: "default"
+    password: "${env:CONF_TEST_MYSQL_PASSWORD}"
+asynq:
+  - instance: "default"
+    redis_password: "redis:${env:CONF_TEST_REDIS_PASSWORD}"
+    sentinel:
+      password: "${env:CONF_TEST_REDIS_PASSWORD}"
+`)
+
+	var cfg secretTestConfig
+	if err := NewLoader().File(path).Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Password != "redis-secret" {
+		t.Fatalf("password = %q, want redis-secret", cfg.Password)
+	}
+	if len(cfg.MySQL) != 1 || cfg.MySQL[0].Password.Value() != "mysql-secret" {
+		t.Fatalf("mysql = %+v, want the password resolved", cfg.MySQL)
+	}
+	if len(cfg.Asynq) != 1 || cfg.Asynq[0].RedisPassword != "redis:redis-secret" || cfg.Asynq[0].Sentinel.Password != "redis-secret" {
+		t.Fatalf("asynq = %+v, want the passwords resolved", cfg.Asynq)
+	}
+}
+
+func TestResolveSecretsReportsListPath(t *testing.T) {
+	path := writeConfig(t, `
+mysql:
+  - instance: "default"
+  - instance: "replica"
+    password: "${env:CONF_TEST_UNSET_PASSWORD}"
+`)
+
+	var cfg secretTestConfig
+	err := NewLoader().File(path).Load(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "mysql[1].password") {
+		t.Fatalf("Load() error = %v, want it to name mysql[1].password", err)
+	}
+}
@@ -0,0 +1,278 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Source supplies configuration values as a nested map keyed by configuration
+// key (the mapstructure tag names). Sources are merged by a Loader in the order
+// they were added; values from later sources override earlier ones.
+type Source interface {
+	// Name identifies the source in origin reports, e.g. "file:config.yaml".
+	Name() string
+	// Load reads the configuration values.
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// fileSource reads a single configuration file in any format supported by viper.
+type fileSource struct {
+	path     string
+	optional bool
+}
+
+// FileSource returns a Source that reads the given configuration file.
+// If optional is true, a missing file yields no values instead of an error.
+func FileSource(path string, optional bool) Source {
+	return &fileSource{path: path, optional: optional}
+}
+
+func (s *fileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *fileSource) Load(_ context.Context) (map[string]any, error) {
+	if s.optional {
+		if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(s.path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read configs file %s: %w", s.path, err)
+	}
+	return v.AllSettings(), nil
+}
+
+// overlayPath returns the environment-specific overlay of a configuration file,
+// e.g. config.yaml with env "prod" becomes config.prod.yaml.
+func overlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// dirFiles returns the configuration files in dir sorted by name.
+// A missing directory yields no files.
+func dirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read configs directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.TrimPrefix(filepath.Ext(entry.Name()), ".")
+		if slices.Contains(viper.SupportedExts, ext) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// defaultsSource supplies the values of `default:"..."` struct tags.
+type defaultsSource struct {
+	typ reflect.Type
+}
+
+func (s *defaultsSource) Name() string {
+	return "defaults"
+}
+
+func (s *defaultsSource) Load(_ context.Context) (map[string]any, error) {
+	values := make(map[string]any)
+	if s.typ == nil {
+		return values, nil
+	}
+	walkFields(s.typ, "", func(key string, field reflect.StructField) {
+		if def, ok := field.Tag.Lookup("default"); ok {
+			setNested(values, key, def)
+		}
+	})
+	return values, nil
+}
+
+// envSource supplies values from environment variables named
+// <PREFIX>_<KEY>, where dots and dashes in the key are replaced with underscores.
+type envSource struct {
+	prefix string
+	keys   []string
+	// vars records the environment variable that supplied each key.
+	vars map[string]string
+}
+
+func (s *envSource) Name() string {
+	if s.prefix == "" {
+		return "env"
+	}
+	return "env:" + s.prefix
+}
+
+func (s *envSource) Load(_ context.Context) (map[string]any, error) {
+	values := make(map[string]any)
+	s.vars = make(map[string]string)
+	for _, key := range s.keys {
+		name := EnvName(s.prefix, key)
+		if value, ok := os.LookupEnv(name); ok {
+			setNested(values, key, value)
+			s.vars[key] = name
+		}
+	}
+	return values, nil
+}
+
+// EnvName returns the environment variable bound to a configuration key,
+// e.g. EnvName("GO_KIT", "server.host") returns "GO_KIT_SERVER_HOST".
+func EnvName(prefix, key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// flagSource supplies the values of command-line flags that were explicitly set.
+// A flag named "server.port" sets the key server.port; dashes may be used in
+// place of underscores.
+type flagSource struct {
+	fs   *flag.FlagSet
+	keys []string
+}
+
+func (s *flagSource) Name() string {
+	return "flags"
+}
+
+func (s *flagSource) Load(_ context.Context) (map[string]any, error) {
+	values := make(map[string]any)
+	known := make(map[string]bool, len(s.keys))
+	for _, key := range s.keys {
+		known[key] = true
+	}
+
+	s.fs.Visit(func(f *flag.Flag) {
+		key := strings.ToLower(f.Name)
+		if !known[key] {
+			key = strings.ReplaceAll(key, "-", "_")
+		}
+		if known[key] {
+			setNested(values, key, f.Value.String())
+		}
+	})
+	return values, nil
+}
+
+// walkFields calls fn for every leaf field of the struct type t with its
+// dotted configuration key. Embedded and `,squash` fields are flattened.
+func walkFields(t reflect.Type, prefix string, fn func(key string, field reflect.StructField)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, squash, ok := fieldKey(field, prefix)
+		if !ok {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if squash && ft.Kind() == reflect.Struct {
+			walkFields(ft, prefix, fn)
+			continue
+		}
+
+		if ft.Kind() == reflect.Struct && !isLeafStruct(ft) {
+			walkFields(ft, key, fn)
+			continue
+		}
+		fn(key, field)
+	}
+}
+
+// fieldKey returns the dotted configuration key of a struct field below prefix
+// and whether the field is squashed into its parent. ok is false for fields
+// tagged `mapstructure:"-"`.
+func fieldKey(field reflect.StructField, prefix string) (key string, squash, ok bool) {
+	tag := field.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false, false
+	}
+
+	squash = strings.Contains(opts, "squash") || (field.Anonymous && name == "")
+	if name == "" {
+		name = field.Name
+	}
+	key = strings.ToLower(name)
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	return key, squash, true
+}
+
+// isLeafStruct reports whether a struct type is decoded from a single value,
+// such as time.Time or types implementing encoding.TextUnmarshaler.
+func isLeafStruct(t reflect.Type) bool {
+	textUnmarshaler := reflect.TypeFor[interface{ UnmarshalText([]byte) error }]()
+	return t.PkgPath() == "time" || reflect.PointerTo(t).Implements(textUnmarshaler)
+}
+
+// setNested sets a dotted key in a nested map, creating intermediate maps.
+func setNested(m map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// flatten returns the dotted keys of all leaf values in a nested map.
+func flatten(m map[string]any, prefix string, out map[string]any) {
+	for k, v := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			flatten(nested, key, out)
+			continue
+		}
+		out[key] = v
+	}
+}
@@ -0,0 +1,133 @@
+package conf
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type layeredTestConfig struct {
+	Server struct {
+		Host    string `mapstructure:"host" default:"0.0.0.0"`
+		Port    int    `mapstructure:"port" default:"8080"`
+		Mode    string `mapstructure:"mode" default:"debug"`
+		Timeout string `mapstructure:"timeout" default:"1s"`
+		Name    string `mapstructure:"name"`
+		Workers int    `mapstructure:"max_workers" default:"1"`
+	} `mapstructure:"server"`
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":         "server:\n  port: 8081\n  mode: release\n  timeout: 2s\n  name: base\n  max_workers: 2\n",
+		"config.prod.yaml":    "server:\n  mode: prod\n  timeout: 3s\n  name: overlay\n  max_workers: 3\n",
+		"conf.d/10-a.yaml":    "server:\n  timeout: 4s\n  name: a\n  max_workers: 4\n",
+		"conf.d/20-b.yaml":    "server:\n  name: b\n  max_workers: 5\n",
+		"conf.d/ignored.text": "server:\n  name: ignored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ORDER_SERVER_NAME", "env")
+	t.Setenv("ORDER_SERVER_MAX_WORKERS", "6")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("server.max-workers", 0, "")
+	fs.String("server.host", "", "")
+	if err := fs.Parse([]string{"-server.max-workers=7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg layeredTestConfig
+	loader := NewLoader().
+		Defaults().
+		File(filepath.Join(dir, "config.yaml")).
+		Overlay("prod").
+		Overlay("").
+		Dir(filepath.Join(dir, "conf.d")).
+		Dir(filepath.Join(dir, "missing")).
+		Env("ORDER").
+		Flags(fs)
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		origin string
+	}{
+		// 后添加的配置源覆盖先添加的配置源，未显式设置的参数不参与合并
+		{key: "server.host", got: cfg.Server.Host, want: "0.0.0.0", origin: "defaults"},
+		{key: "server.port", got: cfg.Server.Port, want: 8081, origin: "file:" + filepath.Join(dir, "config.yaml")},
+		{key: "server.mode", got: cfg.Server.Mode, want: "prod", origin: "file:" + filepath.Join(dir, "config.prod.yaml")},
+		{key: "server.timeout", got: cfg.Server.Timeout, want: "4s", origin: "file:" + filepath.Join(dir, "conf.d", "10-a.yaml")},
+		{key: "server.name", got: cfg.Server.Name, want: "env", origin: "env:ORDER_SERVER_NAME"},
+		{key: "server.max_workers", got: cfg.Server.Workers, want: 7, origin: "flags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("%s = %v, want %v", tt.key, tt.got, tt.want)
+			}
+			if got := loader.Origin(tt.key); got != tt.origin {
+				t.Fatalf("Origin(%q) = %q, want %q", tt.key, got, tt.origin)
+			}
+		})
+	}
+
+	if got := len(loader.Keys()); got != len(tests) {
+		t.Fatalf("Keys() = %v, want %d keys", loader.Keys(), len(tests))
+	}
+}
+
+func TestLoaderSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		loader *Loader
+	}{
+		{name: "missing file", loader: NewLoader().File(filepath.Join(dir, "missing.yaml"))},
+		{name: "invalid file", loader: NewLoader().File(writeConfig(t, "server: [\n"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg layeredTestConfig
+			if err := tt.loader.Load(&cfg); err == nil {
+				t.Fatal("Load() error = nil, want an error")
+			}
+		})
+	}
+
+	var cfg layeredTestConfig
+	if err := NewLoader().OptionalFile(filepath.Join(dir, "missing.yaml")).Load(&cfg); err != nil {
+		t.Fatalf("Load() of a missing optional file error = %v", err)
+	}
+	if err := NewLoader().Load(cfg); err == nil {
+		t.Fatal("Load() of a non-pointer error = nil, want an error")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		prefix, key, want string
+	}{
+		{prefix: "GO_KIT", key: "server.host", want: "GO_KIT_SERVER_HOST"},
+		{prefix: "ORDER", key: "kafka.consumer-group", want: "ORDER_KAFKA_CONSUMER_GROUP"},
+		{key: "port", want: "PORT"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.prefix, tt.key); got != tt.want {
+			t.Fatalf("EnvName(%q, %q) = %q, want %q", tt.prefix, tt.key, got, tt.want)
+		}
+	}
+}
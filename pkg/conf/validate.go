@@ -0,0 +1,126 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// getValidator returns the shared validator. Field paths in validation errors
+// use the mapstructure tag names so that they match the configuration keys.
+func getValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			switch name {
+			case "-":
+				return ""
+			case "":
+				return strings.ToLower(field.Name)
+			default:
+				return name
+			}
+		})
+	})
+	return validate
+}
+
+// RegisterValidation registers a custom validation function for the given tag.
+// It must be called before any configuration is loaded.
+func RegisterValidation(tag string, fn validator.Func) error {
+	return getValidator().RegisterValidation(tag, fn)
+}
+
+// FieldError describes a single configuration field that failed validation.
+type FieldError struct {
+	// Path is the configuration key of the field, e.g. "mysql[0].addr".
+	Path string
+	// Tag is the validation rule that failed, e.g. "required".
+	Tag string
+	// Param is the parameter of the rule, e.g. "65535" for "max=65535".
+	Param string
+}
+
+// Error implements the error interface. Field values are omitted so that
+// secrets never appear in error messages.
+func (e *FieldError) Error() string {
+	if e.Param != "" {
+		return fmt.Sprintf("%s: failed on '%s=%s'", e.Path, e.Tag, e.Param)
+	}
+	return fmt.Sprintf("%s: failed on '%s'", e.Path, e.Tag)
+}
+
+// ValidationError lists every configuration field that failed validation.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return "invalid configs: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual field errors.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// Validate checks the `validate:"..."` struct tags of obj and returns a
+// *ValidationError listing every invalid field. Nested structs are validated
+// automatically; elements of slices and maps require the "dive" rule.
+// Values that are not structs are not validated.
+func Validate(obj any) error {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := getValidator().Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	verr := &ValidationError{Fields: make([]*FieldError, 0, len(fieldErrs))}
+	for _, fe := range fieldErrs {
+		// Namespace 以结构体类型名开头，去掉后即为配置键
+		path := fe.Namespace()
+		if _, rest, ok := strings.Cut(path, "."); ok {
+			path = rest
+		}
+		verr.Fields = append(verr.Fields, &FieldError{
+			Path:  path,
+			Tag:   fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+	return verr
+}
@@ -0,0 +1,186 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is the quiet period after the last file event before a reload
+// is triggered, so that editors writing a file in several steps cause one reload.
+const watchDebounce = 100 * time.Millisecond
+
+// fileWatcher watches configuration files and directories for changes.
+// Parent directories are watched instead of the files themselves so that
+// atomic renames and Kubernetes ConfigMap symlink swaps are detected.
+type fileWatcher struct {
+	watcher  *fsnotify.Watcher
+	files    map[string]bool
+	dirs     map[string]bool
+	onChange func()
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// watchFiles calls onChange after any of the given files, or any file in the
+// given directories, is written, created, removed or renamed.
+func watchFiles(files, dirs []string, onChange func()) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create configs watcher: %w", err)
+	}
+
+	fw := &fileWatcher{
+		watcher:  watcher,
+		files:    make(map[string]bool),
+		dirs:     make(map[string]bool),
+		onChange: onChange,
+		done:     make(chan struct{}),
+	}
+
+	watched := make(map[string]bool)
+	add := func(dir string) error {
+		if watched[dir] {
+			return nil
+		}
+		watched[dir] = true
+		return watcher.Add(dir)
+	}
+
+	for _, file := range files {
+		file = filepath.Clean(file)
+		fw.files[file] = true
+		if err := add(filepath.Dir(file)); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch configs file %s: %w", file, err)
+		}
+	}
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		fw.dirs[dir] = true
+		// a missing fragment directory is ignored by the Loader as well
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch configs directory %s: %w", dir, err)
+		}
+	}
+
+	go fw.run()
+	return fw, nil
+}
+
+// run dispatches file events until the watcher is closed. onChange is called
+// in this goroutine, so reloads never overlap; events arriving during a reload
+// start a new quiet period and cause one more reload.
+func (fw *fileWatcher) run() {
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-fw.watcher.Events:
+			if !ok {
+				return
+			}
+			if !fw.relevant(event) {
+				continue
+			}
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			fw.onChange()
+		case _, ok := <-fw.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-fw.done:
+			return
+		}
+	}
+}
+
+// relevant reports whether an event concerns a watched file or directory.
+func (fw *fileWatcher) relevant(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
+		!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	name := filepath.Clean(event.Name)
+	dir := filepath.Dir(name)
+	// Kubernetes 通过替换 ..data 符号链接更新 ConfigMap，目录下的任何变化都视为相关
+	return fw.files[name] || fw.dirs[dir] || filepath.Base(name) == "..data"
+}
+
+// Close stops watching.
+func (fw *fileWatcher) Close() error {
+	var err error
+	fw.stopOnce.Do(func() {
+		close(fw.done)
+		err = fw.watcher.Close()
+	})
+	return err
+}
+
+// loaderWatch watches the files, fragment directories and watchable sources
+// of a Loader.
+type loaderWatch struct {
+	files  *fileWatcher
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// watchLoader calls onChange after any source of loader changes. Calls are
+// serialized across sources, so onChange never runs concurrently.
+func watchLoader(loader *Loader, onChange func()) (*loaderWatch, error) {
+	files, dirs := loader.watchPaths()
+
+	var mu sync.Mutex
+	changed := onChange
+	onChange = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		changed()
+	}
+
+	lw := &loaderWatch{}
+	if len(files) > 0 || len(dirs) > 0 {
+		fw, err := watchFiles(files, dirs, onChange)
+		if err != nil {
+			return nil, err
+		}
+		lw.files = fw
+	}
+
+	var ctx context.Context
+	ctx, lw.cancel = context.WithCancel(context.Background())
+	for _, source := range loader.watchableSources() {
+		lw.wg.Add(1)
+		go func() {
+			defer lw.wg.Done()
+			source.Watch(ctx, onChange)
+		}()
+	}
+	return lw, nil
+}
+
+// Close stops watching and waits for the source watchers to return.
+func (lw *loaderWatch) Close() error {
+	lw.cancel()
+	lw.wg.Wait()
+	if lw.files != nil {
+		return lw.files.Close()
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reloadRecorder counts onChange calls and the largest number of calls running at once
+type reloadRecorder struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	calls   int
+	running int
+	maxRun  int
+}
+
+func (r *reloadRecorder) onChange() {
+	r.mu.Lock()
+	r.calls++
+	r.running++
+	r.maxRun = max(r.maxRun, r.running)
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+}
+
+func (r *reloadRecorder) snapshot() (calls, maxRun int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls, r.maxRun
+}
+
+func watchTestFile(t *testing.T, onChange func()) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("port: 8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := watchFiles([]string{path}, nil, onChange)
+	if err != nil {
+		t.Fatalf("watchFiles() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fw.Close() })
+	return path
+}
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("port: 8081\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchFilesDebounce(t *testing.T) {
+	r := &reloadRecorder{}
+	path := watchTestFile(t, r.onChange)
+
+	// 间隔短于静默期的多次写入只触发一次重新加载
+	for range 5 {
+		touch(t, path)
+		time.Sleep(watchDebounce / 5)
+	}
+	time.Sleep(3 * watchDebounce)
+
+	if calls, _ := r.snapshot(); calls != 1 {
+		t.Fatalf("onChange called %d times, want 1", calls)
+	}
+}
+
+func TestWatchFilesNoConcurrentReloads(t *testing.T) {
+	r := &reloadRecorder{delay: 4 * watchDebounce}
+	path := watchTestFile(t, r.onChange)
+
+	// 每次写入都在静默期之后，但都发生在上一次重新加载结束之前
+	for range 3 {
+		touch(t, path)
+		time.Sleep(2 * watchDebounce)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		calls, _ := r.snapshot()
+		if calls >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(r.delay)
+
+	calls, maxRun := r.snapshot()
+	if maxRun != 1 {
+		t.Fatalf("%d reloads ran concurrently, want 1", maxRun)
+	}
+	if calls < 2 {
+		t.Fatalf("onChange called %d times, want the changes during a reload to cause another reload", calls)
+	}
+}
+
+// tickingSource is a WatchableSource reporting a change every interval
+type tickingSource struct {
+	name     string
+	interval time.Duration
+}
+
+func (s *tickingSource) Name() string                                 { return s.name }
+func (s *tickingSource) Load(context.Context) (map[string]any, error) { return nil, nil }
+
+func (s *tickingSource) Watch(ctx context.Context, onChange func()) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			onChange()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func TestWatchLoaderSerializesSources(t *testing.T) {
+	r := &reloadRecorder{delay: 20 * time.Millisecond}
+	loader := NewLoader().
+		Add(&tickingSource{name: "a", interval: 5 * time.Millisecond}).
+		Add(&tickingSource{name: "b", interval: 5 * time.Millisecond})
+	lw, err := watchLoader(loader, r.onChange)
+	if err != nil {
+		t.Fatalf("watchLoader() error = %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	_ = lw.Close()
+
+	calls, maxRun := r.snapshot()
+	if calls < 2 || maxRun != 1 {
+		t.Fatalf("onChange called %d times with up to %d at once, want several calls one at a time", calls, maxRun)
+	}
+}
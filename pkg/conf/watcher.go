@@ -0,0 +1,223 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Watcher holds an immutable snapshot of a configuration of type T and swaps
+// in a fully built new snapshot whenever the configuration changes.
+//
+// Readers call Load to get the current snapshot and must treat it as
+// read-only; a snapshot is never modified after it has been published, so it
+// can be read concurrently without locking.
+type Watcher[T any] struct {
+	loader  *Loader
+	current atomic.Pointer[T]
+
+	tracker *reloadTracker
+
+	// reloadMu serializes reloads
+	reloadMu sync.Mutex
+	// mu protects the subscribers and the file watcher
+	mu     sync.Mutex
+	subs   map[int]func(old, new *T)
+	nextID int
+
+	watch *loaderWatch
+}
+
+// NewWatcher loads the initial snapshot from loader.
+// The loader must not be used elsewhere afterwards.
+// Reload failures and subscriber panics are reported through the options and
+// counted in Status.
+func NewWatcher[T any](loader *Loader, opts ...WatchOption) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		loader:  loader,
+		tracker: newReloadTracker(opts),
+		subs:    make(map[int]func(old, new *T)),
+	}
+
+	cfg := new(T)
+	if err := loader.Load(cfg); err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+	return w, nil
+}
+
+// Load returns the current snapshot. The returned value must not be modified.
+func (w *Watcher[T]) Load() *T {
+	return w.current.Load()
+}
+
+// Status returns the outcome of the reloads so far.
+func (w *Watcher[T]) Status() ReloadStatus {
+	return w.tracker.Status()
+}
+
+// Subscribe registers fn to be called with the old and new snapshots after
+// every successful reload. Subscribers are called in registration order from
+// the goroutine performing the reload; a panicking subscriber is recovered and
+// reported without affecting the others. The returned function unsubscribes.
+func (w *Watcher[T]) Subscribe(fn func(old, new *T)) (unsubscribe func()) {
+	w.mu.Lock()
+	id := w.nextID
+	w.nextID++
+	w.subs[id] = fn
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		delete(w.subs, id)
+		w.mu.Unlock()
+	}
+}
+
+// Reload loads a new snapshot and, if loading and validation succeed, swaps it
+// in and notifies subscribers. On failure the current snapshot is kept.
+// The returned error is the load failure or the joined subscriber panics.
+func (w *Watcher[T]) Reload(ctx context.Context) error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg := new(T)
+	if err := w.loader.LoadContext(ctx, cfg); err != nil {
+		w.tracker.failed(err)
+		return err
+	}
+
+	old := w.current.Swap(cfg)
+	w.tracker.succeeded()
+
+	var errs []error
+	for i, fn := range w.subscribers() {
+		if err := w.tracker.call(i, func() { fn(old, cfg) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribers returns the subscribers in registration order.
+func (w *Watcher[T]) subscribers() []func(old, new *T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ids := make([]int, 0, len(w.subs))
+	for id := range w.subs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	subs := make([]func(old, new *T), len(ids))
+	for i, id := range ids {
+		subs[i] = w.subs[id]
+	}
+	return subs
+}
+
+// Watch starts watching the loader's files, fragment directories and remote
+// sources and reloads on change. It returns immediately; call Close to stop
+// watching. Failures of these reloads are only visible through the logger,
+// the error handler and Status.
+func (w *Watcher[T]) Watch() error {
+	lw, err := watchLoader(w.loader, func() {
+		_ = w.Reload(context.Background())
+	})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	previous := w.watch
+	w.watch = lw
+	w.mu.Unlock()
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+	return nil
+}
+
+// Close stops watching for changes.
+func (w *Watcher[T]) Close() error {
+	w.mu.Lock()
+	lw := w.watch
+	w.watch = nil
+	w.mu.Unlock()
+
+	if lw == nil {
+		return nil
+	}
+	return lw.Close()
+}
+
+// Diff returns the configuration keys whose values differ between two
+// snapshots, sorted. Keys use the mapstructure tag names; slices and maps are
+// compared as a whole. A nil snapshot is treated as the zero value.
+func Diff[T any](old, cur *T) []string {
+	if old == nil {
+		old = new(T)
+	}
+	if cur == nil {
+		cur = new(T)
+	}
+
+	before := make(map[string]any)
+	after := make(map[string]any)
+	flattenValue(reflect.ValueOf(old).Elem(), "", before)
+	flattenValue(reflect.ValueOf(cur).Elem(), "", after)
+
+	var changed []string
+	for key, value := range after {
+		if prev, ok := before[key]; !ok || !reflect.DeepEqual(prev, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// flattenValue records the leaf values of v under their dotted keys,
+// following the same key rules as walkFields.
+func flattenValue(v reflect.Value, prefix string, out map[string]any) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			out[prefix] = nil
+			return
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct || isLeafStruct(v.Type()) {
+		out[prefix] = v.Interface()
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, squash, ok := fieldKey(field, prefix)
+		if !ok {
+			continue
+		}
+		if squash {
+			flattenValue(v.Field(i), prefix, out)
+			continue
+		}
+		flattenValue(v.Field(i), key, out)
+	}
+}
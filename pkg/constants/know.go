@@ -4,6 +4,8 @@ package constants
 const (
 	// RequestIDKey 是请求ID在上下文和HTTP头中的键名
 	RequestIDKey = "request_id"
+	// RequestIDHeader 是携带请求ID的HTTP头，gRPC 元数据使用其小写形式
+	RequestIDHeader = "X-Request-ID"
 )
 
 // RequestIDCtx 请求ID上下文
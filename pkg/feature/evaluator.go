@@ -0,0 +1,65 @@
+package feature
+
+import (
+	"context"
+	"strings"
+)
+
+// Provider 提供开关定义
+type Provider interface {
+	// Flag 返回指定名称的开关，不存在时返回 false
+	// 开关名称不区分大小写，实现应按小写名称查找
+	Flag(name string) (Flag, bool)
+}
+
+// Evaluator 使用一次请求的属性计算开关
+type Evaluator struct {
+	provider Provider
+	attrs    Attributes
+}
+
+// NewEvaluator 创建求值器，provider 为 nil 时所有开关都关闭
+func NewEvaluator(provider Provider, attrs Attributes) *Evaluator {
+	return &Evaluator{provider: provider, attrs: attrs}
+}
+
+// Enabled 判断开关是否开启，不存在的开关视为关闭
+// 开关名称不区分大小写，因为配置中的键会被转换为小写
+func (e *Evaluator) Enabled(name string) bool {
+	if e == nil || e.provider == nil {
+		return false
+	}
+	name = strings.ToLower(name)
+	flag, ok := e.provider.Flag(name)
+	if !ok {
+		return false
+	}
+	return flag.Evaluate(name, e.attrs)
+}
+
+// Attributes 返回求值时使用的请求属性
+func (e *Evaluator) Attributes() Attributes {
+	if e == nil {
+		return Attributes{}
+	}
+	return e.attrs
+}
+
+// evaluatorCtx 是求值器在 context 中的键
+type evaluatorCtx struct{}
+
+// NewContext 返回携带求值器的 context
+func NewContext(ctx context.Context, e *Evaluator) context.Context {
+	return context.WithValue(ctx, evaluatorCtx{}, e)
+}
+
+// FromContext 返回 context 中的求值器，不存在时返回 nil，nil 求值器的所有开关都关闭
+func FromContext(ctx context.Context) *Evaluator {
+	e, _ := ctx.Value(evaluatorCtx{}).(*Evaluator)
+	return e
+}
+
+// Enabled 使用 context 中的求值器判断开关是否开启
+func Enabled(ctx context.Context, name string) bool {
+	return FromContext(ctx).Enabled(name)
+}
@@ -0,0 +1,157 @@
+// Package feature 提供功能开关（feature flag），支持布尔开关、按百分比灰度发布以及按用户、
+// 租户、请求头等属性定向开启，开关定义可以来自配置热重载、Redis 或内存。
+package feature
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strings"
+)
+
+// 请求属性相关的 HTTP 头和 gRPC 元数据键
+const (
+	// UserIDHeader 是携带用户 ID 的请求头
+	UserIDHeader = "X-User-ID"
+	// TenantHeader 是携带租户 ID 的请求头
+	TenantHeader = "X-Tenant-ID"
+)
+
+// 规则中可以使用的属性名称
+const (
+	// AttrUserID 匹配用户 ID
+	AttrUserID = "user_id"
+	// AttrTenant 匹配租户 ID
+	AttrTenant = "tenant"
+	// AttrHeaderPrefix 是请求头属性的前缀，例如 "header:X-Beta"
+	AttrHeaderPrefix = "header:"
+)
+
+// Flag 定义一个功能开关
+//
+// 求值顺序：
+//  1. Enabled 为 false 时关闭
+//  2. 按顺序匹配 Rules，第一条匹配的规则决定结果：Exclude 为 false 时开启，否则关闭
+//  3. 设置了 Percentage 时，按用户 ID（没有时使用租户 ID）稳定地分桶，落在比例内的开启
+//  4. 没有设置 Percentage 时，没有规则的开关对所有请求开启，有规则的开关只对匹配规则的请求开启
+type Flag struct {
+	// Enabled 是开关的总开关
+	Enabled bool `mapstructure:"enabled" json:"enabled" desc:"Master switch; a disabled flag is off for every request"`
+	// Percentage 是灰度比例，取值 0-100，为空表示不按比例灰度
+	Percentage *float64 `mapstructure:"percentage" json:"percentage,omitempty" validate:"omitempty,gte=0,lte=100" desc:"Rollout percentage of users, 0-100"`
+	// Rules 是定向规则
+	Rules []Rule `mapstructure:"rules" json:"rules,omitempty" validate:"dive" desc:"Targeting rules, the first matching rule decides"`
+}
+
+// Rule 定义一条定向规则
+type Rule struct {
+	// Attribute 是匹配的属性：user_id、tenant、header:<Name> 或自定义属性名
+	Attribute string `mapstructure:"attribute" json:"attribute" validate:"required" desc:"user_id, tenant, header:<Name> or a custom attribute"`
+	// Values 是匹配的属性值，任一值相等即匹配
+	Values []string `mapstructure:"values" json:"values" validate:"required,min=1" desc:"Attribute values that match the rule"`
+	// Exclude 为 true 时匹配的请求关闭开关
+	Exclude bool `mapstructure:"exclude" json:"exclude,omitempty" desc:"Turn the flag off for matching requests"`
+}
+
+// Flags 是开关名称到开关定义的映射，可以直接嵌入到配置结构体中，
+// 开关名称不区分大小写（配置中的键会被转换为小写）：
+//
+//	type Config struct {
+//	  Features feature.Flags `mapstructure:"features"`
+//	}
+type Flags map[string]Flag
+
+// Attributes 是求值时使用的请求属性
+type Attributes struct {
+	// UserID 是用户 ID，也是百分比灰度的分桶依据
+	UserID string
+	// Tenant 是租户 ID
+	Tenant string
+	// Header 是请求头或 gRPC 元数据
+	Header http.Header
+	// Custom 是自定义属性
+	Custom map[string]string
+}
+
+// AttributesFromHeader 从请求头中提取属性，用户 ID 和租户 ID 分别来自
+// X-User-ID 和 X-Tenant-ID
+func AttributesFromHeader(header http.Header) Attributes {
+	return Attributes{
+		UserID: header.Get(UserIDHeader),
+		Tenant: header.Get(TenantHeader),
+		Header: header,
+	}
+}
+
+// Get 返回属性值
+func (a Attributes) Get(attribute string) (string, bool) {
+	switch {
+	case attribute == AttrUserID:
+		return a.UserID, a.UserID != ""
+	case attribute == AttrTenant:
+		return a.Tenant, a.Tenant != ""
+	case strings.HasPrefix(attribute, AttrHeaderPrefix):
+		values := a.Header.Values(strings.TrimPrefix(attribute, AttrHeaderPrefix))
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	default:
+		value, ok := a.Custom[attribute]
+		return value, ok
+	}
+}
+
+// Evaluate 按属性计算开关是否开启，name 用于百分比分桶，使不同开关的灰度用户相互独立
+func (f Flag) Evaluate(name string, attrs Attributes) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	for _, rule := range f.Rules {
+		if rule.matches(attrs) {
+			return !rule.Exclude
+		}
+	}
+
+	if f.Percentage != nil {
+		key := attrs.UserID
+		if key == "" {
+			key = attrs.Tenant
+		}
+		return inRollout(name, key, *f.Percentage)
+	}
+
+	return len(f.Rules) == 0
+}
+
+func (r Rule) matches(attrs Attributes) bool {
+	value, ok := attrs.Get(r.Attribute)
+	if !ok {
+		return false
+	}
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// inRollout 判断 key 是否落在开关 name 的灰度比例内，同一个 key 的结果是稳定的；
+// 没有 key 时只有比例为 100 才开启
+func inRollout(name, key string, percentage float64) bool {
+	if percentage >= 100 {
+		return true
+	}
+	if percentage <= 0 || key == "" {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(key))
+	// 以 0.01% 为粒度分桶
+	bucket := h.Sum32() % 10000
+	return float64(bucket) < percentage*100
+}
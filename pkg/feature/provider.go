@@ -0,0 +1,70 @@
+package feature
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/yanking/gomicro/pkg/conf"
+)
+
+// MemoryProvider 是基于内存的开关提供者，适用于测试和本地开发
+type MemoryProvider struct {
+	mu    sync.RWMutex
+	flags Flags
+}
+
+// NewMemoryProvider 创建内存开关提供者，开关名称不区分大小写
+func NewMemoryProvider(flags Flags) *MemoryProvider {
+	p := &MemoryProvider{flags: make(Flags, len(flags))}
+	for name, flag := range flags {
+		p.flags[strings.ToLower(name)] = flag
+	}
+	return p
+}
+
+// Flag 实现 Provider 接口
+func (p *MemoryProvider) Flag(name string) (Flag, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	flag, ok := p.flags[strings.ToLower(name)]
+	return flag, ok
+}
+
+// Set 设置开关
+func (p *MemoryProvider) Set(name string, flag Flag) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flags[strings.ToLower(name)] = flag
+}
+
+// Delete 删除开关
+func (p *MemoryProvider) Delete(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.flags, strings.ToLower(name))
+}
+
+// confProvider 从配置快照中读取开关
+type confProvider[T any] struct {
+	watcher *conf.Watcher[T]
+	flags   func(cfg *T) Flags
+}
+
+// NewConfProvider 创建从配置读取开关的提供者，每次求值都读取 watcher 的当前快照，
+// 因此配置热重载后立即生效。配置中的键会被转换为小写，因此按小写名称查找开关
+//
+//	w, _ := conf.NewWatcher[Config](loader)
+//	provider := feature.NewConfProvider(w, func(cfg *Config) feature.Flags { return cfg.Features })
+func NewConfProvider[T any](watcher *conf.Watcher[T], flags func(cfg *T) Flags) Provider {
+	return &confProvider[T]{watcher: watcher, flags: flags}
+}
+
+// Flag 实现 Provider 接口
+func (p *confProvider[T]) Flag(name string) (Flag, bool) {
+	cfg := p.watcher.Load()
+	if cfg == nil {
+		return Flag{}, false
+	}
+	flag, ok := p.flags(cfg)[strings.ToLower(name)]
+	return flag, ok
+}
@@ -0,0 +1,45 @@
+package feature
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yanking/gomicro/pkg/conf"
+)
+
+func TestFlagNamesAreCaseInsensitive(t *testing.T) {
+	type config struct {
+		Features Flags `mapstructure:"features"`
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("features:\n  NewCheckout:\n    enabled: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := conf.NewWatcher[config](conf.NewLoader().File(path))
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	memory := NewMemoryProvider(Flags{"NewCheckout": {Enabled: true}})
+	providers := map[string]Provider{
+		"conf":   NewConfProvider(w, func(cfg *config) Flags { return cfg.Features }),
+		"memory": memory,
+	}
+	for name, provider := range providers {
+		t.Run(name, func(t *testing.T) {
+			e := NewEvaluator(provider, Attributes{})
+			for _, flag := range []string{"NewCheckout", "newcheckout", "NEWCHECKOUT"} {
+				if !e.Enabled(flag) {
+					t.Fatalf("Enabled(%q) = false, want true", flag)
+				}
+			}
+		})
+	}
+
+	memory.Delete("NEWCHECKOUT")
+	if _, ok := memory.Flag("newcheckout"); ok {
+		t.Fatal("flag still present after Delete with a different case")
+	}
+}
@@ -0,0 +1,235 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yanking/gomicro/pkg/client/database"
+)
+
+const (
+	defaultRedisKey     = "feature:flags"
+	defaultPollInterval = 10 * time.Second
+)
+
+// RedisProvider 是从 Redis hash 读取开关的提供者，hash 的字段是开关名称（不区分大小写），值是开关的 JSON：
+//
+//	HSET feature:flags new-checkout '{"enabled":true,"percentage":10}'
+//
+// RedisProvider 实现了 lifecycle.Component，启动时加载一次开关，之后定期刷新；
+// 求值只读取内存中的快照，刷新失败时继续使用上一次成功加载的开关。
+type RedisProvider struct {
+	client        redis.UniversalClient
+	redisInstance string
+	key           string
+	interval      time.Duration
+	logger        *slog.Logger
+
+	flags atomic.Pointer[Flags]
+
+	started  atomic.Bool
+	ready    chan struct{}
+	stopping chan struct{}
+	stopOnce sync.Once
+	loopDone chan struct{}
+}
+
+// RedisOption 定义 RedisProvider 选项函数
+type RedisOption func(*RedisProvider)
+
+// WithRedisClient 设置 Redis 客户端，默认使用 database.GetRedis 返回的客户端
+func WithRedisClient(client redis.UniversalClient) RedisOption {
+	return func(p *RedisProvider) {
+		p.client = client
+	}
+}
+
+// WithRedisInstance 设置默认 Redis 客户端的实例名称，默认为 "default"
+func WithRedisInstance(instance string) RedisOption {
+	return func(p *RedisProvider) {
+		p.redisInstance = instance
+	}
+}
+
+// WithRedisKey 设置存放开关的 hash 键，默认为 "feature:flags"
+func WithRedisKey(key string) RedisOption {
+	return func(p *RedisProvider) {
+		p.key = key
+	}
+}
+
+// WithPollInterval 设置刷新间隔，默认 10 秒
+func WithPollInterval(interval time.Duration) RedisOption {
+	return func(p *RedisProvider) {
+		if interval > 0 {
+			p.interval = interval
+		}
+	}
+}
+
+// NewRedisProvider 创建 Redis 开关提供者
+func NewRedisProvider(logger *slog.Logger, opts ...RedisOption) *RedisProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &RedisProvider{
+		redisInstance: "default",
+		key:           defaultRedisKey,
+		interval:      defaultPollInterval,
+		ready:         make(chan struct{}),
+		stopping:      make(chan struct{}),
+		loopDone:      make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.logger = logger.With(
+		slog.String("component", "feature"),
+		slog.String("key", p.key),
+	)
+	return p
+}
+
+// Flag 实现 Provider 接口
+func (p *RedisProvider) Flag(name string) (Flag, bool) {
+	flags := p.flags.Load()
+	if flags == nil {
+		return Flag{}, false
+	}
+	flag, ok := (*flags)[name]
+	return flag, ok
+}
+
+// Start 加载开关并定期刷新，阻塞直至组件被停止；首次加载失败时返回错误
+func (p *RedisProvider) Start(ctx context.Context) error {
+	if !p.started.CompareAndSwap(false, true) {
+		return errors.New("feature redis provider already started")
+	}
+	defer close(p.loopDone)
+
+	if p.client == nil {
+		p.client = database.GetRedis(p.redisInstance)
+		if p.client == nil {
+			return fmt.Errorf("redis instance '%s' not initialized", p.redisInstance)
+		}
+	}
+
+	if err := p.Refresh(ctx); err != nil {
+		return err
+	}
+	close(p.ready)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil {
+				p.logger.Warn("Failed to refresh feature flags, keeping the current flags", slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			return nil
+		case <-p.stopping:
+			return nil
+		}
+	}
+}
+
+// Stop 停止刷新
+func (p *RedisProvider) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stopping) })
+
+	if p.started.Load() {
+		select {
+		case <-p.loopDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Name 返回组件名称
+func (p *RedisProvider) Name() string {
+	return "feature:redis"
+}
+
+// LongRunning 声明 Start 会阻塞直至组件停止
+func (p *RedisProvider) LongRunning() bool {
+	return true
+}
+
+// Ready 返回一个在首次加载开关成功后被关闭的通道
+func (p *RedisProvider) Ready() <-chan struct{} {
+	return p.ready
+}
+
+// Refresh 立即从 Redis 重新加载开关；无法解析的开关会被跳过并记录日志
+func (p *RedisProvider) Refresh(ctx context.Context) error {
+	if p.client == nil {
+		return errors.New("feature redis provider not started")
+	}
+
+	values, err := p.client.HGetAll(ctx, p.key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load feature flags from '%s': %w", p.key, err)
+	}
+
+	flags := make(Flags, len(values))
+	for name, value := range values {
+		var flag Flag
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			p.logger.Warn("Skipping invalid feature flag",
+				slog.String("flag", name),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		flags[strings.ToLower(name)] = flag
+	}
+	p.flags.Store(&flags)
+	return nil
+}
+
+// Set 把开关写入 Redis 并立即刷新本地快照，其他实例在下一次刷新时生效
+func (p *RedisProvider) Set(ctx context.Context, name string, flag Flag) error {
+	if p.client == nil {
+		return errors.New("feature redis provider not started")
+	}
+
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	name = strings.ToLower(name)
+	if err := p.client.HSet(ctx, p.key, name, data).Err(); err != nil {
+		return fmt.Errorf("failed to save feature flag '%s': %w", name, err)
+	}
+	return p.Refresh(ctx)
+}
+
+// Delete 从 Redis 删除开关并立即刷新本地快照
+func (p *RedisProvider) Delete(ctx context.Context, name string) error {
+	if p.client == nil {
+		return errors.New("feature redis provider not started")
+	}
+
+	name = strings.ToLower(name)
+	if err := p.client.HDel(ctx, p.key, name).Err(); err != nil {
+		return fmt.Errorf("failed to delete feature flag '%s': %w", name, err)
+	}
+	return p.Refresh(ctx)
+}
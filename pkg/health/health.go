@@ -0,0 +1,304 @@
+// Package health provides application-wide health and readiness aggregation.
+// It defines the Checker interface and a Registry that runs registered checks
+// with per-check timeouts and result caching, so that HTTP probes and the gRPC
+// health service can be driven by the same set of checks.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeout  = 3 * time.Second
+	defaultCacheTTL = 5 * time.Second
+)
+
+// errDraining 表示应用正在下线
+var errDraining = errors.New("application is draining")
+
+// ErrDegraded 表示检查项降级但仍可提供服务
+// 检查函数返回包装了 ErrDegraded 的错误时，检查结果为 StatusDegraded 而不是 StatusDown
+var ErrDegraded = errors.New("degraded")
+
+// Status 表示检查结果的状态
+type Status string
+
+const (
+	// StatusUp 表示检查通过
+	StatusUp Status = "up"
+	// StatusDown 表示检查未通过
+	StatusDown Status = "down"
+	// StatusDegraded 表示检查项降级，例如部分组件正在重启，但仍可提供服务
+	StatusDegraded Status = "degraded"
+)
+
+// Checker 定义健康检查接口
+type Checker interface {
+	// Name 返回检查项名称，在同一个 Registry 中必须唯一
+	Name() string
+	// Check 执行检查，返回 nil 表示健康
+	Check(ctx context.Context) error
+}
+
+// checkerFunc 将函数适配为 Checker
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c *checkerFunc) Name() string {
+	return c.name
+}
+
+func (c *checkerFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// NewChecker 使用名称和检查函数创建一个 Checker
+func NewChecker(name string, fn func(ctx context.Context) error) Checker {
+	return &checkerFunc{name: name, fn: fn}
+}
+
+// Result 是单个检查项的执行结果
+type Result struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report 是一组检查项的汇总结果
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Up 返回汇总结果是否可以提供服务，StatusDegraded 也视为可以提供服务
+func (r *Report) Up() bool {
+	return r.Status == StatusUp || r.Status == StatusDegraded
+}
+
+// CheckOption 定义检查项的注册选项
+type CheckOption func(*check)
+
+// WithTimeout 设置检查项的超时时间，默认 3 秒
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// WithCacheTTL 设置检查结果的缓存时间，默认 5 秒，为 0 时每次都重新检查
+func WithCacheTTL(ttl time.Duration) CheckOption {
+	return func(c *check) {
+		c.ttl = ttl
+	}
+}
+
+// WithLiveness 将检查项同时用于存活检查
+// 默认情况下检查项只参与就绪检查，存活检查失败通常会导致实例被重启，应只用于进程自身无法恢复的故障
+func WithLiveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// check 表示一个已注册的检查项
+type check struct {
+	checker  Checker
+	timeout  time.Duration
+	ttl      time.Duration
+	liveness bool
+
+	// mu 保证同一检查项同一时间只执行一次，并保护缓存的结果
+	mu     sync.Mutex
+	last   Result
+	hasRun bool
+}
+
+// run 执行检查，在缓存有效期内直接返回缓存的结果
+// 只缓存检查项自身的结果（包括超时），调用方的 ctx 已结束时结果不会被缓存
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hasRun && c.ttl > 0 && time.Since(c.last.CheckedAt) < c.ttl {
+		return c.last
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(checkCtx)
+	if err == nil && checkCtx.Err() != nil {
+		err = checkCtx.Err()
+	}
+
+	result := Result{
+		Name:      c.checker.Name(),
+		Status:    StatusUp,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		if errors.Is(err, ErrDegraded) {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+	}
+
+	// 调用方取消导致的失败不代表依赖的状态，不写入缓存
+	if ctx.Err() == nil {
+		c.last = result
+		c.hasRun = true
+	}
+	return result
+}
+
+// Registry 管理应用的所有检查项
+type Registry struct {
+	mu       sync.RWMutex
+	checks   map[string]*check
+	draining atomic.Bool
+}
+
+// NewRegistry 创建一个新的 Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checks: make(map[string]*check),
+	}
+}
+
+// Register 注册检查项，同名的检查项会被替换
+func (r *Registry) Register(checker Checker, opts ...CheckOption) {
+	c := &check{
+		checker: checker,
+		timeout: defaultTimeout,
+		ttl:     defaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	r.checks[checker.Name()] = c
+	r.mu.Unlock()
+}
+
+// Unregister 移除指定名称的检查项
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.checks, name)
+	r.mu.Unlock()
+}
+
+// Names 返回所有检查项的名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDraining 设置应用是否正在下线，下线期间就绪检查始终返回 StatusDown
+func (r *Registry) SetDraining(draining bool) {
+	r.draining.Store(draining)
+}
+
+// Draining 返回应用是否正在下线
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Liveness 执行所有存活检查项
+func (r *Registry) Liveness(ctx context.Context) *Report {
+	return r.run(ctx, func(c *check) bool { return c.liveness })
+}
+
+// Readiness 执行所有检查项，应用下线期间直接返回 StatusDown
+func (r *Registry) Readiness(ctx context.Context) *Report {
+	report := r.run(ctx, func(*check) bool { return true })
+	if r.draining.Load() {
+		report.Status = StatusDown
+		report.Checks = append(report.Checks, Result{
+			Name:      "draining",
+			Status:    StatusDown,
+			Error:     errDraining.Error(),
+			CheckedAt: time.Now(),
+		})
+	}
+	return report
+}
+
+// Check 执行指定名称的检查项
+func (r *Registry) Check(ctx context.Context, names ...string) (*Report, error) {
+	r.mu.RLock()
+	for _, name := range names {
+		if _, ok := r.checks[name]; !ok {
+			r.mu.RUnlock()
+			return nil, fmt.Errorf("health check '%s' not registered", name)
+		}
+	}
+	r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	return r.run(ctx, func(c *check) bool { return wanted[c.checker.Name()] }), nil
+}
+
+// run 并发执行满足条件的检查项并汇总结果
+func (r *Registry) run(ctx context.Context, filter func(c *check) bool) *Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if filter(c) {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	// 任一检查项未通过时为 StatusDown，否则任一检查项降级时为 StatusDegraded
+	report := &Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		switch result.Status {
+		case StatusUp:
+		case StatusDegraded:
+			report.Status = StatusDegraded
+		default:
+			report.Status = StatusDown
+			return report
+		}
+	}
+	return report
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingChecker 记录 Check 被调用的次数，并返回 err 中保存的错误
+type countingChecker struct {
+	name  string
+	calls atomic.Int32
+	err   atomic.Value
+	// block 为 true 时 Check 阻塞直至 ctx 结束
+	block bool
+}
+
+func (c *countingChecker) Name() string { return c.name }
+
+func (c *countingChecker) Check(ctx context.Context) error {
+	c.calls.Add(1)
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if err, ok := c.err.Load().(error); ok {
+		return err
+	}
+	return nil
+}
+
+func (c *countingChecker) fail(err error) {
+	c.err.Store(err)
+}
+
+func TestRegistryAggregation(t *testing.T) {
+	errDown := errors.New("down")
+	errDegraded := fmt.Errorf("restarting: %w", ErrDegraded)
+	tests := []struct {
+		name   string
+		errs   []error
+		want   Status
+		wantUp bool
+	}{
+		{name: "no checks", want: StatusUp, wantUp: true},
+		{name: "all up", errs: []error{nil, nil}, want: StatusUp, wantUp: true},
+		{name: "degraded", errs: []error{nil, errDegraded}, want: StatusDegraded, wantUp: true},
+		{name: "down", errs: []error{nil, errDown}, want: StatusDown},
+		{name: "down wins over degraded", errs: []error{errDegraded, errDown, nil}, want: StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for i, err := range tt.errs {
+				r.Register(NewChecker(fmt.Sprintf("c%d", i), func(context.Context) error { return err }))
+			}
+
+			report := r.Readiness(context.Background())
+			if report.Status != tt.want || report.Up() != tt.wantUp {
+				t.Fatalf("Status, Up() = %s, %t, want %s, %t", report.Status, report.Up(), tt.want, tt.wantUp)
+			}
+			if len(report.Checks) != len(tt.errs) {
+				t.Fatalf("got %d results, want %d", len(report.Checks), len(tt.errs))
+			}
+		})
+	}
+}
+
+func TestRegistryLivenessAndDraining(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewChecker("db", func(context.Context) error { return errors.New("down") }))
+	r.Register(NewChecker("deadlock", func(context.Context) error { return nil }), WithLiveness())
+
+	if report := r.Liveness(context.Background()); report.Status != StatusUp || len(report.Checks) != 1 {
+		t.Fatalf("Liveness() = %+v, want only the liveness check, up", report)
+	}
+
+	r.Unregister("db")
+	r.SetDraining(true)
+	if report := r.Readiness(context.Background()); report.Status != StatusDown {
+		t.Fatalf("Readiness() status = %s while draining, want %s", report.Status, StatusDown)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	c := &countingChecker{name: "db"}
+	r := NewRegistry()
+	r.Register(c, WithCacheTTL(time.Hour))
+
+	r.Readiness(context.Background())
+	c.fail(errors.New("down"))
+	if report := r.Readiness(context.Background()); report.Status != StatusUp || c.calls.Load() != 1 {
+		t.Fatalf("status = %s after %d calls, want the cached up result after 1 call", report.Status, c.calls.Load())
+	}
+
+	r.Register(c, WithCacheTTL(0))
+	r.Readiness(context.Background())
+	if report := r.Readiness(context.Background()); report.Status != StatusDown || c.calls.Load() != 3 {
+		t.Fatalf("status = %s after %d calls, want down after 3 calls without a cache", report.Status, c.calls.Load())
+	}
+}
+
+func TestCheckCacheTimeout(t *testing.T) {
+	c := &countingChecker{name: "db", block: true}
+	r := NewRegistry()
+	r.Register(c, WithTimeout(10*time.Millisecond), WithCacheTTL(time.Hour))
+
+	// 检查项自身超时的结果会被缓存
+	if report := r.Readiness(context.Background()); report.Status != StatusDown {
+		t.Fatalf("Readiness() status = %s, want %s after the check timed out", report.Status, StatusDown)
+	}
+	r.Readiness(context.Background())
+	if calls := c.calls.Load(); calls != 1 {
+		t.Fatalf("Check called %d times, want the timeout to be cached", calls)
+	}
+}
+
+func TestCheckCacheSkipsCancelledCaller(t *testing.T) {
+	c := &countingChecker{name: "db"}
+	r := NewRegistry()
+	r.Register(c, WithCacheTTL(time.Hour))
+
+	// 调用方取消时的结果不代表依赖的状态，不应被缓存
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Readiness(ctx)
+
+	if report := r.Readiness(context.Background()); report.Status != StatusUp || c.calls.Load() != 2 {
+		t.Fatalf("status = %s after %d calls, want a fresh up result", report.Status, c.calls.Load())
+	}
+}
@@ -0,0 +1,83 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLeaseLost 表示租约已过期或已被其他实例持有
+var ErrLeaseLost = errors.New("leader: lease lost")
+
+// Backend 定义租约存储后端接口
+// 实现必须保证同一时刻最多只有一个持有者，并且每次成功获取租约返回的 fencing token 单调递增
+type Backend interface {
+	// Acquire 尝试以 holder 的身份获取 key 对应的租约，有效期为 ttl
+	// 获取成功时返回新的 fencing token 和 true；租约被其他实例持有时返回 false
+	Acquire(ctx context.Context, key, holder string, ttl time.Duration) (token uint64, acquired bool, err error)
+	// Renew 续约，租约已过期或已被其他实例持有时返回 ErrLeaseLost
+	Renew(ctx context.Context, key, holder string, ttl time.Duration) error
+	// Release 释放租约，租约不属于 holder 时不做任何操作
+	Release(ctx context.Context, key, holder string) error
+}
+
+// MemoryBackend 是基于内存的租约后端，只在单个进程内有效，适用于测试和本地开发
+type MemoryBackend struct {
+	mu     sync.Mutex
+	leases map[string]*memoryLease
+	tokens map[string]uint64
+}
+
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// NewMemoryBackend 创建一个新的 MemoryBackend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		leases: make(map[string]*memoryLease),
+		tokens: make(map[string]uint64),
+	}
+}
+
+// Acquire 尝试获取租约
+func (b *MemoryBackend) Acquire(_ context.Context, key, holder string, ttl time.Duration) (uint64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if lease, ok := b.leases[key]; ok && lease.holder != holder && now.Before(lease.expiresAt) {
+		return 0, false, nil
+	}
+
+	b.leases[key] = &memoryLease{holder: holder, expiresAt: now.Add(ttl)}
+	b.tokens[key]++
+	return b.tokens[key], true, nil
+}
+
+// Renew 续约
+func (b *MemoryBackend) Renew(_ context.Context, key, holder string, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	lease, ok := b.leases[key]
+	if !ok || lease.holder != holder || !now.Before(lease.expiresAt) {
+		return ErrLeaseLost
+	}
+	lease.expiresAt = now.Add(ttl)
+	return nil
+}
+
+// Release 释放租约
+func (b *MemoryBackend) Release(_ context.Context, key, holder string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lease, ok := b.leases[key]; ok && lease.holder == holder {
+		delete(b.leases, key)
+	}
+	return nil
+}
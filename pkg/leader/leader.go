@@ -0,0 +1,378 @@
+// Package leader provides leader election for components that must run on
+// exactly one replica, such as schedulers and cron-style jobs.
+// An Elector wraps a lifecycle.Component, campaigns for a lease stored in a
+// pluggable Backend (Redis by default) and only runs the wrapped component
+// while holding the lease. Each successful acquisition yields a monotonically
+// increasing fencing token that the wrapped code can attach to its writes.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/client/database"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+)
+
+const (
+	defaultTTL         = 15 * time.Second
+	defaultStopTimeout = 10 * time.Second
+)
+
+// errUnexpectedExit 表示长期运行的组件在持有领导权期间退出
+var errUnexpectedExit = errors.New("component exited unexpectedly while leading")
+
+// tokenKey 是 fencing token 在上下文中的键
+type tokenKey struct{}
+
+// TokenFromContext 返回被包装组件的 Start 上下文中携带的 fencing token
+// 被包装的组件应在写入共享资源时带上该值，使存储端可以拒绝来自旧领导者的写入
+func TokenFromContext(ctx context.Context) (uint64, bool) {
+	token, ok := ctx.Value(tokenKey{}).(uint64)
+	return token, ok
+}
+
+// Elector 是只在持有领导权时运行被包装组件的组件
+// Elector 本身实现了 lifecycle.Component，可以像普通组件一样交给 App 管理。
+// 失去领导权后被包装的组件会被停止，随后重新竞选，因此被包装的组件需要支持在 Stop 之后再次调用 Start。
+type Elector struct {
+	key           string
+	component     lifecycle.Component
+	backend       Backend
+	redisInstance string
+	holder        string
+	ttl           time.Duration
+	renewInterval time.Duration
+	retryInterval time.Duration
+	stopTimeout   time.Duration
+	logger        *slog.Logger
+
+	leading atomic.Bool
+	token   atomic.Uint64
+
+	// mu 保护当前任期
+	mu   sync.Mutex
+	term *term
+
+	started  atomic.Bool
+	stopping chan struct{}
+	stopOnce sync.Once
+	loopDone chan struct{}
+}
+
+// term 表示一次持有领导权期间被包装组件的运行
+type term struct {
+	token  uint64
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// New 创建一个新的 Elector，key 是租约名称，同一个 key 在所有副本中最多只有一个领导者
+func New(logger *slog.Logger, key string, component lifecycle.Component, opts ...Option) *Elector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	e := &Elector{
+		key:           key,
+		component:     component,
+		redisInstance: "default",
+		ttl:           defaultTTL,
+		stopTimeout:   defaultStopTimeout,
+		stopping:      make(chan struct{}),
+		loopDone:      make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.holder == "" {
+		e.holder = defaultHolderID()
+	}
+	if e.renewInterval <= 0 {
+		e.renewInterval = e.ttl / 3
+	}
+	if e.retryInterval <= 0 {
+		e.retryInterval = e.ttl / 3
+	}
+	e.logger = logger.With(
+		slog.String("component", "leader"),
+		slog.String("key", key),
+		slog.String("holder", e.holder),
+	)
+
+	return e
+}
+
+// Start 竞选领导权，并在持有领导权期间运行被包装的组件，直到 ctx 被取消或 Stop 被调用
+// 被包装的组件启动失败或意外退出时释放领导权并返回错误
+func (e *Elector) Start(ctx context.Context) error {
+	if !e.started.CompareAndSwap(false, true) {
+		return errors.New("leader elector already started")
+	}
+	defer close(e.loopDone)
+
+	if e.backend == nil {
+		client := database.GetRedis(e.redisInstance)
+		if client == nil {
+			return fmt.Errorf("redis instance '%s' not initialized", e.redisInstance)
+		}
+		e.backend = NewRedisBackend(client)
+	}
+
+	for {
+		token, acquiredAt, ok := e.campaign(ctx)
+		if !ok {
+			return nil
+		}
+
+		if err := e.lead(ctx, token, acquiredAt); err != nil {
+			return err
+		}
+
+		if !e.wait(ctx, e.retryInterval) {
+			return nil
+		}
+	}
+}
+
+// Stop 停止被包装的组件并释放领导权
+func (e *Elector) Stop(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stopping) })
+
+	err := e.resign(ctx)
+
+	if e.started.Load() {
+		select {
+		case <-e.loopDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// Name 返回组件名称
+func (e *Elector) Name() string {
+	return "leader:" + e.component.Name()
+}
+
+// LongRunning 声明 Start 会阻塞直至 Elector 停止
+func (e *Elector) LongRunning() bool {
+	return true
+}
+
+// DependsOn 返回被包装组件的依赖
+func (e *Elector) DependsOn() []string {
+	if dep, ok := e.component.(lifecycle.Dependent); ok {
+		return dep.DependsOn()
+	}
+	return nil
+}
+
+// IsLeader 返回当前实例是否持有领导权
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Token 返回当前任期的 fencing token，未持有领导权时返回 false
+func (e *Elector) Token() (uint64, bool) {
+	if !e.leading.Load() {
+		return 0, false
+	}
+	return e.token.Load(), true
+}
+
+// campaign 持续尝试获取租约，直到成功或 Elector 被停止
+// 返回 fencing token 和发起获取请求的时间，租约的有效期从不早于该时间开始计算
+func (e *Elector) campaign(ctx context.Context) (uint64, time.Time, bool) {
+	for {
+		acquiredAt := time.Now()
+		token, acquired, err := e.backend.Acquire(ctx, e.key, e.holder, e.ttl)
+		switch {
+		case err != nil:
+			e.logger.Warn("Failed to acquire leadership", slog.Any("error", err))
+		case acquired:
+			return token, acquiredAt, true
+		}
+
+		if !e.wait(ctx, e.retryInterval) {
+			return 0, time.Time{}, false
+		}
+	}
+}
+
+// lead 启动被包装的组件并定期续约，直到失去领导权或 Elector 被停止
+// 续约失败且下一次续约前租约可能过期时主动让出领导权，为停止被包装的组件留出一个续约间隔
+// 只有被包装的组件失败时返回错误
+func (e *Elector) lead(ctx context.Context, token uint64, acquiredAt time.Time) error {
+	leaderCtx, cancel := context.WithCancel(context.WithValue(ctx, tokenKey{}, token))
+	t := &term{token: token, cancel: cancel, done: make(chan struct{})}
+
+	e.mu.Lock()
+	if e.isStopping() {
+		e.mu.Unlock()
+		cancel()
+		e.release(ctx)
+		return nil
+	}
+	e.term = t
+	e.token.Store(token)
+	e.leading.Store(true)
+	e.mu.Unlock()
+
+	e.logger.Info("Acquired leadership, starting component",
+		slog.String("child", e.component.Name()),
+		slog.Uint64("token", token),
+	)
+
+	go func() {
+		t.err = e.component.Start(leaderCtx)
+		close(t.done)
+	}()
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+	lastRenew := acquiredAt
+	done := t.done
+
+	for {
+		select {
+		case <-ticker.C:
+			renewAt := time.Now()
+			err := e.renew(ctx, lastRenew)
+			if err == nil {
+				lastRenew = renewAt
+				continue
+			}
+			if !errors.Is(err, ErrLeaseLost) && time.Since(lastRenew)+e.renewInterval < e.ttl {
+				e.logger.Warn("Failed to renew leadership", slog.Any("error", err))
+				continue
+			}
+
+			e.logger.Warn("Lost leadership, stopping component", slog.Any("error", err))
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), e.stopTimeout)
+			if err := e.resign(stopCtx); err != nil {
+				e.logger.Error("Failed to stop component after losing leadership", slog.Any("error", err))
+			}
+			stopCancel()
+			return nil
+		case <-done:
+			if ctx.Err() != nil || e.isStopping() {
+				return nil
+			}
+
+			err := t.err
+			if err == nil {
+				lr, ok := e.component.(lifecycle.LongRunning)
+				if !ok || !lr.LongRunning() {
+					// Start 不阻塞的组件在返回后继续运行，继续持有领导权
+					done = nil
+					continue
+				}
+				err = errUnexpectedExit
+			}
+
+			e.logger.Error("Component failed while leading, releasing leadership", slog.Any("error", err))
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), e.stopTimeout)
+			_ = e.resign(stopCtx)
+			stopCancel()
+			return fmt.Errorf("component '%s' failed while leading: %w", e.component.Name(), err)
+		case <-ctx.Done():
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), e.stopTimeout)
+			_ = e.resign(stopCtx)
+			stopCancel()
+			return nil
+		case <-e.stopping:
+			return nil
+		}
+	}
+}
+
+// renew 续约，lastRenew 是上一次成功续约（或获取租约）的时间
+// 超时时间不超过续约间隔，并且在租约过期前至少留出一个续约间隔
+func (e *Elector) renew(ctx context.Context, lastRenew time.Time) error {
+	timeout := min(e.renewInterval, time.Until(lastRenew.Add(e.ttl-e.renewInterval)))
+	if timeout <= 0 {
+		return context.DeadlineExceeded
+	}
+	renewCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return e.backend.Renew(renewCtx, e.key, e.holder, e.ttl)
+}
+
+// resign 停止当前任期内运行的被包装组件并释放租约，未持有领导权时不做任何操作
+func (e *Elector) resign(ctx context.Context) error {
+	e.mu.Lock()
+	t := e.term
+	e.term = nil
+	e.leading.Store(false)
+	e.mu.Unlock()
+
+	if t == nil {
+		return nil
+	}
+
+	err := e.component.Stop(ctx)
+	t.cancel()
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+
+	e.release(ctx)
+	e.logger.Info("Resigned leadership", slog.Uint64("token", t.token))
+	return err
+}
+
+// release 释放租约，失败时租约会在有效期后自动过期
+func (e *Elector) release(ctx context.Context) {
+	if err := e.backend.Release(ctx, e.key, e.holder); err != nil {
+		e.logger.Warn("Failed to release leadership", slog.Any("error", err))
+	}
+}
+
+// wait 等待指定时间，Elector 被停止时返回 false
+func (e *Elector) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-e.stopping:
+		return false
+	}
+}
+
+func (e *Elector) isStopping() bool {
+	select {
+	case <-e.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultHolderID 返回 "<hostname>-<pid>-<random>" 形式的持有者标识
+func defaultHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(buf))
+}
@@ -0,0 +1,159 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// run 是被包装组件的一次运行
+type run struct {
+	token uint64
+	at    time.Time
+}
+
+// job 是测试用的被包装组件：每次运行开始时向 started 发送 fencing token 和开始时间，结束时向 stopped 发送结束时间
+type job struct {
+	name    string
+	started chan run
+	stopped chan time.Time
+}
+
+func newJob(name string) *job {
+	return &job{name: name, started: make(chan run, 4), stopped: make(chan time.Time, 4)}
+}
+
+func (j *job) Start(ctx context.Context) error {
+	token, _ := TokenFromContext(ctx)
+	j.started <- run{token: token, at: time.Now()}
+	<-ctx.Done()
+	j.stopped <- time.Now()
+	return nil
+}
+
+func (j *job) Stop(context.Context) error { return nil }
+func (j *job) Name() string               { return j.name }
+func (j *job) LongRunning() bool          { return true }
+
+// partitionedBackend 模拟与后端的网络分区：开启后 Renew 阻塞直至超时，Acquire 和 Release 失败
+type partitionedBackend struct {
+	Backend
+	partitioned chan struct{}
+}
+
+func (b *partitionedBackend) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (uint64, bool, error) {
+	select {
+	case <-b.partitioned:
+		return 0, false, errors.New("network unreachable")
+	default:
+		return b.Backend.Acquire(ctx, key, holder, ttl)
+	}
+}
+
+func (b *partitionedBackend) Renew(ctx context.Context, key, holder string, ttl time.Duration) error {
+	select {
+	case <-b.partitioned:
+		<-ctx.Done()
+		return ctx.Err()
+	default:
+		return b.Backend.Renew(ctx, key, holder, ttl)
+	}
+}
+
+func (b *partitionedBackend) Release(ctx context.Context, key, holder string) error {
+	select {
+	case <-b.partitioned:
+		return errors.New("network unreachable")
+	default:
+		return b.Backend.Release(ctx, key, holder)
+	}
+}
+
+// elect 创建并在后台运行一个 Elector，测试结束时停止它
+func elect(t *testing.T, backend Backend, component *job) *Elector {
+	e := New(slog.New(slog.DiscardHandler), "jobs", component,
+		WithBackend(backend),
+		WithHolderID(component.name),
+		WithTTL(300*time.Millisecond),
+		WithRenewInterval(60*time.Millisecond),
+		WithRetryInterval(10*time.Millisecond),
+		WithStopTimeout(time.Second),
+	)
+	done := make(chan error, 1)
+	go func() { done <- e.Start(context.Background()) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = e.Stop(ctx)
+		<-done
+	})
+	return e
+}
+
+func TestElectorTakeoverAfterResign(t *testing.T) {
+	backend := NewMemoryBackend()
+	jobA, jobB := newJob("a"), newJob("b")
+	a := elect(t, backend, jobA)
+	runA := <-jobA.started
+	b := elect(t, backend, jobB)
+
+	// 租约有效期内 b 不能接管
+	select {
+	case <-jobB.started:
+		t.Fatal("b took over while a holds the lease")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	stoppedA := <-jobA.stopped
+
+	var runB run
+	select {
+	case runB = <-jobB.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("b did not take over after a resigned")
+	}
+	if !stoppedA.Before(runB.at) {
+		t.Fatal("b started the component before a stopped it")
+	}
+	if runB.token <= runA.token {
+		t.Fatalf("token of b = %d, want greater than token of a %d", runB.token, runA.token)
+	}
+	if token, ok := b.Token(); !b.IsLeader() || !ok || token != runB.token {
+		t.Fatalf("Token() = %d, %v, want %d, true", token, ok, runB.token)
+	}
+}
+
+func TestElectorStepsDownBeforeLeaseExpires(t *testing.T) {
+	memory := NewMemoryBackend()
+	backendA := &partitionedBackend{Backend: memory, partitioned: make(chan struct{})}
+	jobA, jobB := newJob("a"), newJob("b")
+	a := elect(t, backendA, jobA)
+	<-jobA.started
+	elect(t, memory, jobB)
+
+	// a 的续约一直阻塞，租约只能在过期后被 b 获取
+	close(backendA.partitioned)
+
+	var runB run
+	select {
+	case runB = <-jobB.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("b did not take over after the lease of a expired")
+	}
+	select {
+	case stoppedA := <-jobA.stopped:
+		if !stoppedA.Before(runB.at) {
+			t.Fatal("a was still running the component when b took over")
+		}
+	default:
+		t.Fatal("a was still running the component when b took over")
+	}
+	if a.IsLeader() {
+		t.Fatal("a still reports leadership after stepping down")
+	}
+}
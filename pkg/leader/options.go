@@ -0,0 +1,57 @@
+package leader
+
+import "time"
+
+// Option 定义 Elector 选项函数
+type Option func(*Elector)
+
+// WithBackend 设置租约后端，默认使用 database.GetRedis 返回的 Redis 客户端
+func WithBackend(backend Backend) Option {
+	return func(e *Elector) {
+		e.backend = backend
+	}
+}
+
+// WithRedisInstance 设置默认 Redis 租约后端使用的实例名称，默认为 "default"
+func WithRedisInstance(instance string) Option {
+	return func(e *Elector) {
+		e.redisInstance = instance
+	}
+}
+
+// WithTTL 设置租约的有效期，默认 15 秒
+// 持有者崩溃后，其他实例最多需要等待该时间才能接管
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Elector) {
+		e.ttl = ttl
+	}
+}
+
+// WithRenewInterval 设置续约间隔，默认为有效期的三分之一
+// 续约失败时 Elector 在租约过期前至少一个续约间隔让出领导权，因此续约间隔必须小于有效期的一半才能容忍续约失败
+func WithRenewInterval(interval time.Duration) Option {
+	return func(e *Elector) {
+		e.renewInterval = interval
+	}
+}
+
+// WithRetryInterval 设置竞选失败后重试的间隔，默认为有效期的三分之一
+func WithRetryInterval(interval time.Duration) Option {
+	return func(e *Elector) {
+		e.retryInterval = interval
+	}
+}
+
+// WithHolderID 设置当前实例的持有者标识，默认为 "<hostname>-<pid>-<random>"
+func WithHolderID(id string) Option {
+	return func(e *Elector) {
+		e.holder = id
+	}
+}
+
+// WithStopTimeout 设置失去领导权时停止被包装组件的超时时间，默认 10 秒
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(e *Elector) {
+		e.stopTimeout = timeout
+	}
+}
@@ -0,0 +1,89 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript 在租约空闲或已由 holder 持有时获取租约，并递增 fencing token
+// KEYS[1] 是租约键，KEYS[2] 是 fencing token 计数器；ARGV[1] 是持有者，ARGV[2] 是有效期（毫秒）
+var acquireScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and current ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return redis.call('INCR', KEYS[2])
+`)
+
+// renewScript 在租约由 holder 持有时延长有效期
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript 在租约由 holder 持有时删除租约
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisBackend 是基于 Redis 的租约后端
+// 租约和 fencing token 计数器使用相同的 hash tag，在 Redis Cluster 中位于同一个槽
+type RedisBackend struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisBackend 使用 Redis 客户端创建租约后端，通常传入 database.GetRedis() 返回的客户端
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{
+		client: client,
+		prefix: "leader:",
+	}
+}
+
+// Acquire 尝试获取租约
+func (b *RedisBackend) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (uint64, bool, error) {
+	leaseKey, tokenKey := b.keys(key)
+	token, err := acquireScript.Run(ctx, b.client, []string{leaseKey, tokenKey}, holder, ttl.Milliseconds()).Uint64()
+	if err != nil {
+		return 0, false, err
+	}
+	return token, token > 0, nil
+}
+
+// Renew 续约
+func (b *RedisBackend) Renew(ctx context.Context, key, holder string, ttl time.Duration) error {
+	leaseKey, _ := b.keys(key)
+	renewed, err := renewScript.Run(ctx, b.client, []string{leaseKey}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrLeaseLost
+		}
+		return err
+	}
+	if renewed == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release 释放租约
+func (b *RedisBackend) Release(ctx context.Context, key, holder string) error {
+	leaseKey, _ := b.keys(key)
+	return releaseScript.Run(ctx, b.client, []string{leaseKey}, holder).Err()
+}
+
+// keys 返回租约键和 fencing token 计数器键
+func (b *RedisBackend) keys(key string) (string, string) {
+	tag := b.prefix + "{" + key + "}"
+	return tag, tag + ":token"
+}
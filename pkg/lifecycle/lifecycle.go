@@ -20,3 +20,70 @@ type Component interface {
 	// 用于日志记录和调试
 	Name() string
 }
+
+// Dependent 是组件可选实现的接口，用于声明启动依赖
+// App 会在依赖的组件全部就绪后才启动该组件，并在关闭时先于依赖停止该组件
+type Dependent interface {
+	// DependsOn 返回所依赖组件的名称（即依赖组件 Name() 的返回值）
+	DependsOn() []string
+}
+
+// Readiness 是组件可选实现的接口，用于发出就绪信号
+// 未实现该接口的组件中，一次性组件在 Start 成功返回后视为就绪，
+// 实现了 LongRunning 的组件在 Start 被调用且持续运行一小段时间（见 app.WithStartupWindow）后视为就绪
+type Readiness interface {
+	// Ready 返回一个在组件可以对外提供服务时被关闭的通道
+	Ready() <-chan struct{}
+}
+
+// LongRunning 是组件可选实现的接口，声明 Start 会一直阻塞直至组件被停止（例如 HTTP/gRPC 服务器）
+// 对于此类组件，Start 在应用关闭前返回（即使返回 nil）也会被视为意外退出
+type LongRunning interface {
+	LongRunning() bool
+}
+
+// FailurePolicy 定义组件启动失败或意外退出时 App 的处理策略
+type FailurePolicy int
+
+const (
+	// FailFatal 视为致命错误：触发应用的优雅关闭，并由 App.Run 返回错误（默认策略）
+	FailFatal FailurePolicy = iota
+	// FailRestart 等待一段时间后重新启动组件
+	FailRestart
+	// FailIgnore 仅记录日志，应用继续运行
+	FailIgnore
+)
+
+// String 返回策略名称
+func (p FailurePolicy) String() string {
+	switch p {
+	case FailFatal:
+		return "fatal"
+	case FailRestart:
+		return "restart"
+	case FailIgnore:
+		return "ignore"
+	default:
+		return "unknown"
+	}
+}
+
+// FailurePolicyProvider 是组件可选实现的接口，用于声明组件的失败处理策略
+// 未实现该接口的组件使用 FailFatal
+type FailurePolicyProvider interface {
+	FailurePolicy() FailurePolicy
+}
+
+// Drainer 是组件可选实现的接口，在关闭流程的预关闭阶段、停止组件之前被调用
+// 组件应将自身标记为不健康（例如健康检查返回 503），以便负载均衡器摘除流量
+type Drainer interface {
+	Drain()
+}
+
+// Reloadable 是组件可选实现的接口，用于在不重启进程的情况下应用新的配置
+// 收到 SIGHUP 或调用 App.Reload 时，App 会重新读取配置文件，并将新的配置依次推送给实现了该接口的组件
+type Reloadable interface {
+	// Reload 应用新的配置，cfg 是重新解析得到的配置，类型为 *C（C 是 app.ServiceContext 的配置类型）
+	// 返回错误表示组件未能应用新的配置，不会影响其他组件，也不会导致应用退出
+	Reload(ctx context.Context, cfg any) error
+}
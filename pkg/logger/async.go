@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultAsyncBufferSize is the number of records buffered by default.
+const defaultAsyncBufferSize = 1024
+
+// OverflowPolicy decides what an AsyncHandler does with a record when its
+// buffer is full.
+type OverflowPolicy string
+
+const (
+	// PolicyDrop discards the record and counts it as dropped.
+	PolicyDrop OverflowPolicy = "drop"
+	// PolicyBlock waits until the buffer has room for the record.
+	PolicyBlock OverflowPolicy = "block"
+)
+
+// AsyncOptions configures asynchronous logging.
+type AsyncOptions struct {
+	// Enabled writes records in the background instead of in the goroutine
+	// logging them.
+	Enabled bool `mapstructure:"enabled" desc:"Write records in the background instead of in the logging goroutine"`
+	// BufferSize is the number of records buffered.
+	BufferSize int `mapstructure:"buffer_size" default:"1024" validate:"gte=0" desc:"Number of records buffered"`
+	// Policy is applied when the buffer is full: drop the record or block
+	// until there is room for it.
+	Policy OverflowPolicy `mapstructure:"policy" default:"drop" validate:"omitempty,oneof=drop block" desc:"Policy when the buffer is full: drop the record or block until there is room"`
+}
+
+// asyncRecord is a buffered record and the handler writing it.
+type asyncRecord struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+// asyncQueue is the ring buffer and the worker shared by an AsyncHandler and
+// the handlers derived from it.
+type asyncQueue struct {
+	policy OverflowPolicy
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	buf      []asyncRecord
+	head     int
+	count    int
+	closed   bool
+	// buffered and written count the records put into and written from buf
+	buffered uint64
+	written  uint64
+	// flushes are waiting for the records buffered before them
+	flushes []asyncFlush
+
+	dropped atomic.Uint64
+	done    chan struct{}
+}
+
+// asyncFlush is a Flush waiting until written reaches buffered.
+type asyncFlush struct {
+	buffered uint64
+	done     chan struct{}
+}
+
+// AsyncHandler is a slog.Handler that buffers records in a bounded ring
+// buffer and writes them to the next handler in a background goroutine, so
+// that logging does not wait for the output. Loggers derived with With and
+// WithGroup share the buffer.
+//
+// Buffered records are lost if the process exits before they are written;
+// call Flush or Close, or the package-level Close, before exiting. app.New
+// registers the package-level Close as a close function.
+type AsyncHandler struct {
+	next  slog.Handler
+	queue *asyncQueue
+}
+
+// asyncHandlers are the open AsyncHandlers, flushed by Flush and Close.
+var asyncHandlers = struct {
+	sync.Mutex
+	handlers map[*AsyncHandler]struct{}
+}{handlers: make(map[*AsyncHandler]struct{})}
+
+// asyncDropped counts the records dropped by all AsyncHandlers.
+var asyncDropped atomic.Uint64
+
+// NewAsyncHandler creates an AsyncHandler writing to next and starts its
+// worker. opts.Enabled is ignored.
+func NewAsyncHandler(next slog.Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultAsyncBufferSize
+	}
+	if opts.Policy == "" {
+		opts.Policy = PolicyDrop
+	}
+
+	q := &asyncQueue{
+		policy: opts.Policy,
+		buf:    make([]asyncRecord, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	go q.run()
+
+	h := &AsyncHandler{next: next, queue: q}
+	asyncHandlers.Lock()
+	asyncHandlers.handlers[h] = struct{}{}
+	asyncHandlers.Unlock()
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler. The record is buffered, or dropped or
+// waited for according to the policy when the buffer is full. After Close
+// records are written synchronously, once the buffered records have been
+// written.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	q := h.queue
+	q.mu.Lock()
+	for q.count == len(q.buf) && !q.closed {
+		if q.policy != PolicyBlock {
+			q.mu.Unlock()
+			q.dropped.Add(1)
+			asyncDropped.Add(1)
+			return nil
+		}
+		q.notFull.Wait()
+	}
+	if q.closed {
+		q.mu.Unlock()
+		// wait for the buffered records, so that records are written in
+		// the order they were handled
+		<-q.done
+		return h.next.Handle(ctx, r)
+	}
+
+	q.buf[(q.head+q.count)%len(q.buf)] = asyncRecord{
+		handler: h.next,
+		// the record outlives the call, e.g. the request of ctx
+		ctx:    context.WithoutCancel(ctx),
+		record: r.Clone(),
+	}
+	q.count++
+	q.buffered++
+	q.notEmpty.Signal()
+	q.mu.Unlock()
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{next: h.next.WithAttrs(attrs), queue: h.queue}
+}
+
+// WithGroup implements slog.Handler.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{next: h.next.WithGroup(name), queue: h.queue}
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.queue.dropped.Load()
+}
+
+// Buffered returns the number of records waiting to be written.
+func (h *AsyncHandler) Buffered() int {
+	h.queue.mu.Lock()
+	defer h.queue.mu.Unlock()
+	return h.queue.count
+}
+
+// Flush waits until the records buffered before the call have been written
+// or ctx is done.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	q := h.queue
+	q.mu.Lock()
+	if q.written == q.buffered {
+		q.mu.Unlock()
+		return nil
+	}
+	flush := asyncFlush{buffered: q.buffered, done: make(chan struct{})}
+	q.flushes = append(q.flushes, flush)
+	q.mu.Unlock()
+
+	select {
+	case <-flush.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the buffered records and stops the worker. Records handled
+// afterwards are written synchronously after the buffered records, so that
+// nothing logged later during shutdown is lost. It returns when the buffer is empty or ctx is done.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	q := h.queue
+	asyncHandlers.Lock()
+	for handler := range asyncHandlers.handlers {
+		if handler.queue == q {
+			delete(asyncHandlers.handlers, handler)
+		}
+	}
+	asyncHandlers.Unlock()
+
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run writes buffered records until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	q.mu.Lock()
+	for {
+		for q.count == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.count == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		entry := q.buf[q.head]
+		q.buf[q.head] = asyncRecord{}
+		q.head = (q.head + 1) % len(q.buf)
+		q.count--
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		// errors of the output cannot be reported to the caller
+		_ = entry.handler.Handle(entry.ctx, entry.record)
+
+		q.mu.Lock()
+		q.written++
+		q.notifyFlushes()
+	}
+}
+
+// notifyFlushes releases the flushes whose records have been written. q.mu
+// must be held.
+func (q *asyncQueue) notifyFlushes() {
+	waiting := q.flushes[:0]
+	for _, flush := range q.flushes {
+		if flush.buffered <= q.written {
+			close(flush.done)
+		} else {
+			waiting = append(waiting, flush)
+		}
+	}
+	q.flushes = waiting
+}
+
+// Flush waits until the records buffered so far by all open AsyncHandlers
+// have been written or ctx is done.
+func Flush(ctx context.Context) error {
+	var errs []error
+	for _, h := range openAsyncHandlers() {
+		if err := h.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all open AsyncHandlers, writing their buffered records. It is
+// registered as a close function of app.App, so that buffered records are
+// written on shutdown.
+func Close(ctx context.Context) error {
+	var errs []error
+	for _, h := range openAsyncHandlers() {
+		if err := h.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped returns the number of records dropped by all AsyncHandlers
+// because their buffers were full.
+func Dropped() uint64 {
+	return asyncDropped.Load()
+}
+
+func openAsyncHandlers() []*AsyncHandler {
+	asyncHandlers.Lock()
+	defer asyncHandlers.Unlock()
+
+	handlers := make([]*AsyncHandler, 0, len(asyncHandlers.handlers))
+	for h := range asyncHandlers.handlers {
+		handlers = append(handlers, h)
+	}
+	return handlers
+}
@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler 记录写入的消息；设置 gate 时每条记录要等 gate 关闭后才写入，
+// 开始写入时向 handling 发送消息
+type recordingHandler struct {
+	gate     chan struct{}
+	handling chan string
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{gate: make(chan struct{}), handling: make(chan string, 16)}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	select {
+	case h.handling <- r.Message:
+	default:
+	}
+	<-h.gate
+	h.mu.Lock()
+	h.messages = append(h.messages, r.Message)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h *recordingHandler) written() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.messages)
+}
+
+// messages 返回 m0 到 m<n-1>
+func messages(n int) []string {
+	msgs := make([]string, n)
+	for i := range msgs {
+		msgs[i] = fmt.Sprintf("m%d", i)
+	}
+	return msgs
+}
+
+func TestAsyncHandlerFlush(t *testing.T) {
+	next := newRecordingHandler()
+	h := NewAsyncHandler(next, AsyncOptions{BufferSize: 16})
+	defer h.Close(context.Background())
+
+	logger := slog.New(h)
+	for _, msg := range messages(10) {
+		logger.Info(msg)
+	}
+
+	// 输出阻塞时 Flush 在 ctx 结束时返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := h.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(next.gate)
+	if err := h.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := next.written(); !slices.Equal(got, messages(10)) {
+		t.Fatalf("written = %v, want %v", got, messages(10))
+	}
+	if got := h.Buffered(); got != 0 {
+		t.Fatalf("Buffered() = %d after Flush, want 0", got)
+	}
+}
+
+func TestAsyncHandlerClose(t *testing.T) {
+	next := newRecordingHandler()
+	h := NewAsyncHandler(next, AsyncOptions{BufferSize: 16})
+
+	// 派生的 logger 与原 handler 共享缓冲区
+	logger := slog.New(h.WithAttrs([]slog.Attr{slog.String("k", "v")}))
+	for _, msg := range messages(5) {
+		logger.Info(msg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := h.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Close 之后的记录在缓冲的记录之后同步写入
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		slog.New(h).Info("m5")
+	}()
+	timeout := time.After(20 * time.Millisecond)
+	for waiting := true; waiting; {
+		select {
+		case msg := <-next.handling:
+			if msg == "m5" {
+				t.Fatal("record written synchronously before the buffered records")
+			}
+		case <-timeout:
+			waiting = false
+		}
+	}
+
+	close(next.gate)
+	<-logged
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := next.written(); !slices.Equal(got, messages(6)) {
+		t.Fatalf("written = %v, want %v", got, messages(6))
+	}
+}
+
+func TestAsyncHandlerFull(t *testing.T) {
+	tests := []struct {
+		policy      OverflowPolicy
+		wantWritten int
+		wantDropped uint64
+	}{
+		// 第一条记录阻塞在输出上，之后的两条填满缓冲区，其余的被丢弃
+		{policy: PolicyDrop, wantWritten: 3, wantDropped: 3},
+		{policy: PolicyBlock, wantWritten: 6},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			next := newRecordingHandler()
+			h := NewAsyncHandler(next, AsyncOptions{BufferSize: 2, Policy: tt.policy})
+			defer h.Close(context.Background())
+			droppedBefore := Dropped()
+
+			logger := slog.New(h)
+			logger.Info("m0")
+			<-next.handling
+
+			logged := make(chan struct{})
+			go func() {
+				defer close(logged)
+				for _, msg := range messages(6)[1:] {
+					logger.Info(msg)
+				}
+			}()
+			if tt.policy == PolicyBlock {
+				select {
+				case <-logged:
+					t.Fatal("logging did not block on a full buffer")
+				case <-time.After(20 * time.Millisecond):
+				}
+			} else {
+				<-logged
+			}
+
+			close(next.gate)
+			<-logged
+			if err := h.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := next.written(); !slices.Equal(got, messages(tt.wantWritten)) {
+				t.Fatalf("written = %v, want %v", got, messages(tt.wantWritten))
+			}
+			if got := h.Dropped(); got != tt.wantDropped {
+				t.Fatalf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+			if got := Dropped() - droppedBefore; got < tt.wantDropped {
+				t.Fatalf("package Dropped() grew by %d, want at least %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestInitClosesPreviousAsyncHandler(t *testing.T) {
+	config := &Config{Output: io.Discard, Async: &AsyncOptions{Enabled: true}}
+	Init(config)
+	previous := config.async
+
+	Init(DefaultConfig())
+	t.Cleanup(func() { Init(DefaultConfig()) })
+
+	for _, h := range openAsyncHandlers() {
+		if h == previous {
+			t.Fatal("the AsyncHandler of the replaced default logger is still open")
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/yanking/gomicro/pkg/constants"
+)
+
+// Keys of the attributes added from the context.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+	UserIDKey  = "user_id"
+)
+
+// ContextExtractor returns attributes to add to every record logged with a
+// context, e.g. the trace id of an OpenTelemetry span.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// contextFieldsKey is the context key of contextFields.
+type contextFieldsKey struct{}
+
+// contextFields holds the values stored by WithTrace, WithUserID and
+// WithContextAttrs. It is copied on every change.
+type contextFields struct {
+	traceID string
+	spanID  string
+	userID  string
+	attrs   []slog.Attr
+}
+
+func fieldsFromContext(ctx context.Context) contextFields {
+	fields, _ := ctx.Value(contextFieldsKey{}).(contextFields)
+	return fields
+}
+
+// WithRequestID returns a context carrying the request id, which is stored
+// under constants.RequestIDCtx{} like the REST Context middleware does.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, constants.RequestIDCtx{}, requestID)
+}
+
+// RequestIDFromContext returns the request id stored in ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(constants.RequestIDCtx{}).(string)
+	return requestID
+}
+
+// WithTrace returns a context carrying the trace and span ids.
+func WithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	fields := fieldsFromContext(ctx)
+	fields.traceID, fields.spanID = traceID, spanID
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// WithTraceparent returns a context carrying the trace and span ids of a W3C
+// traceparent header ("00-<trace-id>-<span-id>-<flags>"). ctx is returned
+// unchanged if the header is empty or malformed.
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	return WithTrace(ctx, parts[1], parts[2])
+}
+
+// TraceFromContext returns the trace and span ids stored in ctx.
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	fields := fieldsFromContext(ctx)
+	return fields.traceID, fields.spanID
+}
+
+// WithUserID returns a context carrying the user id.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	fields := fieldsFromContext(ctx)
+	fields.userID = userID
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// UserIDFromContext returns the user id stored in ctx.
+func UserIDFromContext(ctx context.Context) string {
+	return fieldsFromContext(ctx).userID
+}
+
+// WithContextAttrs returns a context carrying attrs in addition to the
+// attributes already stored in ctx. They are added to every record logged
+// with the returned context:
+//
+//	ctx = logger.WithContextAttrs(ctx, slog.String("order_id", id))
+//	log.InfoContext(ctx, "Order created")
+func WithContextAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	fields := fieldsFromContext(ctx)
+	fields.attrs = append(fields.attrs[:len(fields.attrs):len(fields.attrs)], attrs...)
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// contextHandler adds the request id, trace and span ids, user id and
+// context attributes of the context passed to the *Context log methods.
+type contextHandler struct {
+	next       slog.Handler
+	extractors []ContextExtractor
+	// hasRequestID reports whether the request id was already added with
+	// logger.With, as the REST Context middleware does
+	hasRequestID bool
+}
+
+func newContextHandler(next slog.Handler, extractors []ContextExtractor) *contextHandler {
+	return &contextHandler{next: next, extractors: extractors}
+}
+
+// Enabled implements slog.Handler.
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.next.Handle(ctx, r)
+	}
+
+	if !h.hasRequestID {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			r.AddAttrs(slog.String(constants.RequestIDKey, requestID))
+		}
+	}
+
+	fields := fieldsFromContext(ctx)
+	if fields.traceID != "" {
+		r.AddAttrs(slog.String(TraceIDKey, fields.traceID))
+	}
+	if fields.spanID != "" {
+		r.AddAttrs(slog.String(SpanIDKey, fields.spanID))
+	}
+	if fields.userID != "" {
+		r.AddAttrs(slog.String(UserIDKey, fields.userID))
+	}
+	r.AddAttrs(fields.attrs...)
+
+	for _, extract := range h.extractors {
+		r.AddAttrs(extract(ctx)...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hasRequestID := h.hasRequestID
+	for _, a := range attrs {
+		if a.Key == constants.RequestIDKey {
+			hasRequestID = true
+		}
+	}
+	return &contextHandler{next: h.next.WithAttrs(attrs), extractors: h.extractors, hasRequestID: hasRequestID}
+}
+
+// WithGroup implements slog.Handler. Context attributes are added inside the
+// group, like any other attribute of the record.
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name), extractors: h.extractors, hasRequestID: h.hasRequestID}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name string
+		ctx  context.Context
+		with []any
+		want map[string]any
+	}{
+		{name: "empty context", ctx: context.Background(), want: map[string]any{}},
+		{
+			name: "request id",
+			ctx:  WithRequestID(context.Background(), "req-1"),
+			want: map[string]any{"request_id": "req-1"},
+		},
+		{
+			name: "trace, user and attributes",
+			ctx: WithContextAttrs(
+				WithUserID(WithTrace(context.Background(), "t1", "s1"), "u1"),
+				slog.String("order_id", "42")),
+			want: map[string]any{"trace_id": "t1", "span_id": "s1", "user_id": "u1", "order_id": "42"},
+		},
+		{
+			name: "traceparent",
+			ctx:  WithTraceparent(context.Background(), traceparent),
+			want: map[string]any{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", "span_id": "00f067aa0ba902b7"},
+		},
+		{name: "malformed traceparent", ctx: WithTraceparent(context.Background(), "00-abc-01"), want: map[string]any{}},
+		{
+			// 已经通过 With 添加的请求 ID 不会重复添加
+			name: "request id added with With",
+			ctx:  WithRequestID(context.Background(), "req-ctx"),
+			with: []any{"request_id", "req-with"},
+			want: map[string]any{"request_id": "req-with"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := newContextHandler(slog.NewJSONHandler(&buf, nil), nil)
+			slog.New(handler).With(tt.with...).InfoContext(tt.ctx, "hello")
+
+			if got := contextAttrs(t, buf.Bytes()); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("attributes = %v, want %v", got, tt.want)
+			}
+			if n := strings.Count(buf.String(), `"request_id"`); n > 1 {
+				t.Fatalf("request_id logged %d times: %s", n, buf.String())
+			}
+		})
+	}
+}
+
+func TestContextHandlerExtractors(t *testing.T) {
+	type tenantKey struct{}
+	extractor := func(ctx context.Context) []slog.Attr {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []slog.Attr{slog.String("tenant", tenant)}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	handler := newContextHandler(slog.NewJSONHandler(&buf, nil), []ContextExtractor{extractor})
+	ctx := WithUserID(context.WithValue(context.Background(), tenantKey{}, "acme"), "u1")
+	slog.New(handler).WithGroup("g").InfoContext(ctx, "hello")
+
+	// 上下文属性与其他属性一样位于分组之内
+	want := map[string]any{"g": map[string]any{"user_id": "u1", "tenant": "acme"}}
+	if got := contextAttrs(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("attributes = %v, want %v", got, want)
+	}
+}
+
+// contextAttrs 返回 JSON 日志中除 time、level 和 msg 之外的属性
+func contextAttrs(t *testing.T, line []byte) map[string]any {
+	t.Helper()
+	var attrs map[string]any
+	if err := json.Unmarshal(line, &attrs); err != nil {
+		t.Fatalf("invalid log line %s: %v", line, err)
+	}
+	for _, key := range []string{slog.TimeKey, slog.LevelKey, slog.MessageKey} {
+		delete(attrs, key)
+	}
+	return attrs
+}
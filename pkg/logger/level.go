@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ModuleKey is the attribute that names the module of a logger. Loggers
+// scoped with logger.With(slog.String("component", "mysql")) use the level of
+// the "mysql" module when one is set.
+const ModuleKey = "component"
+
+// Levels holds the level of a logger and per-module overrides, all of which
+// can be changed at runtime.
+type Levels struct {
+	base *slog.LevelVar
+	// modules holds an immutable snapshot of the overrides so that Enabled
+	// does not take a lock
+	modules atomic.Pointer[map[string]slog.Level]
+	// mu serializes updates of modules
+	mu sync.Mutex
+}
+
+// NewLevels creates Levels using base as the logger level. A nil base
+// creates a new LevelVar at info level.
+func NewLevels(base *slog.LevelVar) *Levels {
+	if base == nil {
+		base = new(slog.LevelVar)
+	}
+	l := &Levels{base: base}
+	l.modules.Store(&map[string]slog.Level{})
+	return l
+}
+
+// LevelVar returns the variable holding the logger level.
+func (l *Levels) LevelVar() *slog.LevelVar {
+	return l.base
+}
+
+// Level returns the logger level.
+func (l *Levels) Level() slog.Level {
+	return l.base.Level()
+}
+
+// SetLevel sets the logger level. Modules with an override keep their level.
+func (l *Levels) SetLevel(level slog.Level) {
+	l.base.Set(level)
+}
+
+// ModuleLevel returns the override of a module.
+func (l *Levels) ModuleLevel(module string) (slog.Level, bool) {
+	level, ok := (*l.modules.Load())[module]
+	return level, ok
+}
+
+// ModuleLevels returns a copy of all module overrides.
+func (l *Levels) ModuleLevels() map[string]slog.Level {
+	return maps.Clone(*l.modules.Load())
+}
+
+// SetModuleLevel overrides the level of a module.
+func (l *Levels) SetModuleLevel(module string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modules := maps.Clone(*l.modules.Load())
+	modules[module] = level
+	l.modules.Store(&modules)
+}
+
+// ResetModuleLevel removes the override of a module, which then uses the
+// logger level again.
+func (l *Levels) ResetModuleLevel(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modules := maps.Clone(*l.modules.Load())
+	delete(modules, module)
+	l.modules.Store(&modules)
+}
+
+// Apply sets the logger level and replaces all module overrides from the
+// level and modules fields of opts, typically after a configuration reload.
+// Nothing is changed if any level is invalid.
+func (l *Levels) Apply(opts *Options) error {
+	level, modules, err := opts.levels()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base.Set(level)
+	l.modules.Store(&modules)
+	return nil
+}
+
+// Enabled reports whether a record of the given level is logged by a logger
+// of the given module.
+func (l *Levels) Enabled(module string, level slog.Level) bool {
+	if module != "" {
+		if minLevel, ok := (*l.modules.Load())[module]; ok {
+			return level >= minLevel
+		}
+	}
+	return level >= l.base.Level()
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error", optionally with an
+// offset such as "debug-2". An empty string is info.
+func ParseLevel(text string) (slog.Level, error) {
+	var level slog.Level
+	if text == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("invalid log level '%s': %w", text, err)
+	}
+	return level, nil
+}
+
+// levelHandler filters records by the level of the module of the logger.
+type levelHandler struct {
+	next   slog.Handler
+	levels *Levels
+	module string
+}
+
+func newLevelHandler(next slog.Handler, levels *Levels) *levelHandler {
+	return &levelHandler{next: next, levels: levels}
+}
+
+// Enabled implements slog.Handler.
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.levels.Enabled(h.module, level)
+}
+
+// Handle implements slog.Handler.
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler. The last ModuleKey attribute sets the
+// module of the returned handler.
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, a := range attrs {
+		if a.Key == ModuleKey {
+			module = a.Value.String()
+		}
+	}
+	return &levelHandler{next: h.next.WithAttrs(attrs), levels: h.levels, module: module}
+}
+
+// WithGroup implements slog.Handler.
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), levels: h.levels, module: h.module}
+}
+
+// levelState is the body of the level HTTP handler.
+type levelState struct {
+	Level   string            `json:"level"`
+	Module  string            `json:"module,omitempty"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// LevelHandler returns an HTTP handler that reads and changes levels:
+//
+//	GET                                   returns {"level": "INFO", "modules": {"mysql": "WARN"}}
+//	PUT {"level": "debug"}                sets the logger level
+//	PUT {"level": "warn", "module": "mysql"} sets the level of a module
+//	DELETE ?module=mysql                  removes the override of a module
+//
+// PUT also accepts the level and module query parameters. Mount it on an
+// internal address only, e.g. with gin.WrapH on the admin server.
+func LevelHandler(levels *Levels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			req := levelState{
+				Level:  r.URL.Query().Get("level"),
+				Module: r.URL.Query().Get("module"),
+			}
+			if req.Level == "" {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Level == "" {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "level is required"})
+					return
+				}
+			}
+
+			level, err := ParseLevel(req.Level)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			if req.Module != "" {
+				levels.SetModuleLevel(req.Module, level)
+			} else {
+				levels.SetLevel(level)
+			}
+		case http.MethodDelete:
+			module := r.URL.Query().Get("module")
+			if module == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "module is required"})
+				return
+			}
+			levels.ResetModuleLevel(module)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, levels.state())
+	})
+}
+
+// state returns the current levels for the HTTP handler.
+func (l *Levels) state() levelState {
+	state := levelState{Level: l.Level().String()}
+	if modules := l.ModuleLevels(); len(modules) > 0 {
+		state.Modules = make(map[string]string, len(modules))
+		for module, level := range modules {
+			state.Modules[module] = level.String()
+		}
+	}
+	return state
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -2,8 +2,11 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -15,6 +18,11 @@ import (
 var (
 	// defaultLogger is the default logger instance
 	defaultLogger *slog.Logger
+	// defaultLevels holds the levels of defaultLogger
+	defaultLevels *Levels
+	// defaultAsync is the AsyncHandler of defaultLogger, closed when Init
+	// replaces it
+	defaultAsync *AsyncHandler
 	// loggerMutex protects defaultLogger during initialization
 	loggerMutex sync.RWMutex
 	// basePath is used to trim the base path from source file paths
@@ -25,6 +33,16 @@ var (
 type Config struct {
 	// Level is the logging level
 	Level slog.Level
+	// LevelVar, if set, is used as the handler level so that the level can be
+	// changed at runtime. It is initialized with Level.
+	LevelVar *slog.LevelVar
+	// Levels, if set, holds the level and the per-module levels of the
+	// logger; LevelVar is then ignored. New creates it when it is nil, so it
+	// can be read back from the Config to change levels at runtime.
+	Levels *Levels
+	// Modules sets the levels of modules, keyed by the ModuleKey attribute,
+	// e.g. {"mysql": slog.LevelWarn}
+	Modules map[string]slog.Level
 	// Format is the log format: "text" or "json"
 	Format string
 	// Output is the log output writer, default to os.Stdout
@@ -35,6 +53,60 @@ type Config struct {
 	BasePath string
 	// AutoDetectBasePath determines whether to automatically detect the base path
 	AutoDetectBasePath bool
+	// Sinks, if set, replace Format and Output: every record passing the
+	// logger level is written to each sink whose own level it passes, e.g.
+	// JSON to a rotating file and text to stdout
+	Sinks []Sink
+	// Redactor removes sensitive data from messages and attributes; nil uses
+	// the default Redactor, see GetRedactor. Init makes it the default.
+	Redactor *Redactor
+	// Sampling, if enabled, samples records by message and level before
+	// they are written, e.g. to limit a log line per query at high traffic
+	Sampling *SampleOptions
+	// Async, if enabled, writes records to the sinks in a background
+	// goroutine through a bounded buffer, see AsyncHandler
+	Async *AsyncOptions
+	// ContextExtractors add attributes from the context of every record, in
+	// addition to the request id, trace and span ids, user id and
+	// WithContextAttrs attributes, which are always added
+	ContextExtractors []ContextExtractor
+
+	// async is the AsyncHandler created by New, closed by Close
+	async *AsyncHandler
+}
+
+// Sink is an output of a logger.
+type Sink struct {
+	// Output is the writer, default to os.Stdout
+	Output io.Writer
+	// Format is the log format: "text" or "json", default to Config.Format
+	Format string
+	// Level, if set, is the minimum level written to the sink; records must
+	// pass the logger level first
+	Level slog.Leveler
+}
+
+// Close writes the records buffered by the asynchronous handler created by
+// New, then closes the outputs of the sinks that implement io.Closer, such
+// as rotating files, except os.Stdout and os.Stderr.
+func (c *Config) Close() error {
+	var errs []error
+	if c.async != nil {
+		if err := c.async.Close(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, sink := range c.Sinks {
+		if sink.Output == os.Stdout || sink.Output == os.Stderr {
+			continue
+		}
+		if closer, ok := sink.Output.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // DefaultConfig returns a default logger configuration
@@ -101,52 +173,68 @@ func New(config *Config) *slog.Logger {
 		basePath = detectBasePath()
 	}
 
-	var handler slog.Handler
-	opts := &slog.HandlerOptions{
-		Level:     config.Level,
-		AddSource: config.AddSource,
+	if config.Levels == nil {
+		config.Levels = NewLevels(config.LevelVar)
+	}
+	config.Levels.SetLevel(config.Level)
+	for module, level := range config.Modules {
+		config.Levels.SetModuleLevel(module, level)
 	}
 
-	// Custom attribute replacement function
-	opts.ReplaceAttr = func(_ []string, a slog.Attr) slog.Attr {
-		// Use RFC3339 time format by default
-		if a.Key == slog.TimeKey {
-			if t, ok := a.Value.Any().(time.Time); ok {
-				a.Value = slog.StringValue(t.Format(time.RFC3339))
-			}
-		}
-
-		// Trim base path from source file paths
-		if a.Key == slog.SourceKey && basePath != "" {
-			if source, ok := a.Value.Any().(*slog.Source); ok && source != nil {
-				if strings.HasPrefix(source.File, basePath) {
-					source.File = filepath.Join("./", strings.TrimPrefix(source.File, basePath))
-					// Remove leading slash if present
-					source.File = strings.TrimPrefix(source.File, "/")
-					// Clean up the path
-					source.File = filepath.Clean(source.File)
-				}
-			}
-		}
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = []Sink{{Output: config.Output, Format: config.Format}}
+	}
 
-		return a
+	handlers := make([]slog.Handler, 0, len(sinks))
+	for _, sink := range sinks {
+		handlers = append(handlers, newSinkHandler(sink, config))
 	}
 
-	if strings.ToLower(config.Format) == "json" {
-		handler = slog.NewJSONHandler(config.Output, opts)
+	var handler slog.Handler
+	if len(handlers) == 1 {
+		handler = handlers[0]
 	} else {
-		handler = slog.NewTextHandler(config.Output, opts)
+		handler = NewFanoutHandler(handlers...)
 	}
 
-	return slog.New(handler)
+	if config.async != nil {
+		// the config was used by New before; its handler would otherwise
+		// never be closed
+		_ = config.async.Close(context.Background())
+		config.async = nil
+	}
+	if config.Async != nil && config.Async.Enabled {
+		config.async = NewAsyncHandler(handler, *config.Async)
+		handler = config.async
+	}
+
+	handler = newRedactHandler(handler, config.Redactor)
+	handler = newContextHandler(handler, config.ContextExtractors)
+	if config.Sampling.Enabled() {
+		handler = NewSampleHandler(handler, *config.Sampling)
+	}
+	return slog.New(newLevelHandler(handler, config.Levels))
 }
 
-// Init initializes the default logger with the provided configuration
+// Init initializes the default logger with the provided configuration. The
+// AsyncHandler of the logger it replaces, if any, is closed, writing its
+// buffered records.
 func Init(config *Config) {
 	loggerMutex.Lock()
-	defer loggerMutex.Unlock()
-
+	if config == nil {
+		config = DefaultConfig()
+	}
+	previous := defaultAsync
 	defaultLogger = New(config)
+	defaultLevels = config.Levels
+	defaultAsync = config.async
+	SetRedactor(config.Redactor)
+	loggerMutex.Unlock()
+
+	if previous != nil && previous != defaultAsync {
+		_ = previous.Close(context.Background())
+	}
 }
 
 // Get returns the default logger instance
@@ -159,7 +247,9 @@ func Get() *slog.Logger {
 	if logger == nil {
 		loggerMutex.Lock()
 		if defaultLogger == nil {
-			defaultLogger = New(DefaultConfig())
+			config := DefaultConfig()
+			defaultLogger = New(config)
+			defaultLevels = config.Levels
 		}
 		logger = defaultLogger
 		loggerMutex.Unlock()
@@ -167,3 +257,67 @@ func Get() *slog.Logger {
 
 	return logger
 }
+
+// GetLevels returns the levels of the default logger, which can be changed
+// at runtime or served with LevelHandler.
+func GetLevels() *Levels {
+	Get()
+
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	return defaultLevels
+}
+
+// newSinkHandler creates the text or JSON handler of a sink.
+func newSinkHandler(sink Sink, config *Config) slog.Handler {
+	output := sink.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	format := sink.Format
+	if format == "" {
+		format = config.Format
+	}
+
+	// the logger level is checked by the level handler
+	var level slog.Leveler = slog.Level(math.MinInt)
+	if sink.Level != nil {
+		level = sink.Level
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		AddSource:   config.AddSource,
+		ReplaceAttr: replaceAttr,
+	}
+	if strings.ToLower(format) == "json" {
+		return slog.NewJSONHandler(output, opts)
+	}
+	return slog.NewTextHandler(output, opts)
+}
+
+// replaceAttr formats times as RFC3339 and trims the base path from source
+// file paths.
+func replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	// Use RFC3339 time format by default
+	if a.Key == slog.TimeKey {
+		if t, ok := a.Value.Any().(time.Time); ok {
+			a.Value = slog.StringValue(t.Format(time.RFC3339))
+		}
+	}
+
+	// Trim base path from source file paths
+	if a.Key == slog.SourceKey && basePath != "" {
+		if source, ok := a.Value.Any().(*slog.Source); ok && source != nil {
+			if strings.HasPrefix(source.File, basePath) {
+				source.File = filepath.Join("./", strings.TrimPrefix(source.File, basePath))
+				// Remove leading slash if present
+				source.File = strings.TrimPrefix(source.File, "/")
+				// Clean up the path
+				source.File = filepath.Clean(source.File)
+			}
+		}
+	}
+
+	return a
+}
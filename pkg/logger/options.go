@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Options is the `logger` section of the configuration file:
+//
+//	logger:
+//	  level: info
+//	  format: json
+//	  modules:
+//	    mysql: warn
+//	    redis: debug
+type Options struct {
+	Level              string            `mapstructure:"level" default:"info" desc:"Log level: debug, info, warn or error, optionally with an offset such as debug-2"`
+	Format             string            `mapstructure:"format" default:"text" validate:"omitempty,oneof=text json" desc:"Log format"`
+	AddSource          bool              `mapstructure:"add_source" desc:"Add the source file and line of the log call"`
+	BasePath           string            `mapstructure:"base_path" desc:"Path trimmed from source file names"`
+	AutoDetectBasePath bool              `mapstructure:"auto_detect_base_path" desc:"Trim source file names up to the directory containing go.mod"`
+	Modules            map[string]string `mapstructure:"modules" desc:"Levels of modules keyed by the component attribute, e.g. mysql: warn"`
+	Outputs            []*OutputOptions  `mapstructure:"outputs" validate:"dive" desc:"Outputs of the logger, defaults to stdout in the logger format"`
+	Redact             RedactOptions     `mapstructure:"redact" desc:"Redaction of sensitive data"`
+	Sampling           SampleOptions     `mapstructure:"sampling" desc:"Sampling of records with the same message and level"`
+	Async              AsyncOptions      `mapstructure:"async" desc:"Asynchronous writing of records through a bounded buffer"`
+}
+
+// OutputOptions configures an output of the logger.
+type OutputOptions struct {
+	Type   string         `mapstructure:"type" validate:"omitempty,oneof=stdout stderr file" desc:"Output type, defaults to stdout"`
+	Format string         `mapstructure:"format" validate:"omitempty,oneof=text json" desc:"Log format, defaults to the logger format"`
+	Level  string         `mapstructure:"level" desc:"Minimum level written to the output, defaults to every record passing the logger level"`
+	File   *RotateOptions `mapstructure:"file" desc:"Rotating file, required when the type is file"`
+}
+
+// Config converts the options into a logger configuration writing to
+// os.Stdout, or to the configured outputs. Files opened for the outputs are
+// closed by Config.Close.
+func (o *Options) Config() (*Config, error) {
+	level, modules, err := o.levels()
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := NewRedactor(o.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	sinks, err := o.sinks()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Level:              level,
+		Format:             o.Format,
+		Output:             os.Stdout,
+		AddSource:          o.AddSource,
+		BasePath:           o.BasePath,
+		AutoDetectBasePath: o.AutoDetectBasePath,
+		Modules:            modules,
+		Sinks:              sinks,
+		Redactor:           redactor,
+		Sampling:           &o.Sampling,
+		Async:              &o.Async,
+	}, nil
+}
+
+// sinks creates the sinks of the outputs, opening the files. Opened files
+// are closed if any output is invalid.
+func (o *Options) sinks() ([]Sink, error) {
+	sinks := make([]Sink, 0, len(o.Outputs))
+	for i, output := range o.Outputs {
+		sink, err := output.sink()
+		if err != nil {
+			_ = (&Config{Sinks: sinks}).Close()
+			return nil, fmt.Errorf("invalid logger output %d: %w", i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// sink creates the sink of the output.
+func (o *OutputOptions) sink() (Sink, error) {
+	sink := Sink{Format: o.Format}
+	if o.Level != "" {
+		level, err := ParseLevel(o.Level)
+		if err != nil {
+			return sink, err
+		}
+		sink.Level = level
+	}
+
+	switch o.Type {
+	case "", "stdout":
+		sink.Output = os.Stdout
+	case "stderr":
+		sink.Output = os.Stderr
+	case "file":
+		if o.File == nil {
+			return sink, errors.New("file options are required for a file output")
+		}
+		file, err := NewRotatingFile(*o.File)
+		if err != nil {
+			return sink, err
+		}
+		sink.Output = file
+	default:
+		return sink, fmt.Errorf("unknown output type '%s'", o.Type)
+	}
+	return sink, nil
+}
+
+// levels parses the level and the module levels.
+func (o *Options) levels() (slog.Level, map[string]slog.Level, error) {
+	level, err := ParseLevel(o.Level)
+	if err != nil {
+		return level, nil, err
+	}
+
+	modules := make(map[string]slog.Level, len(o.Modules))
+	for module, text := range o.Modules {
+		if modules[module], err = ParseLevel(text); err != nil {
+			return level, nil, fmt.Errorf("invalid level of module '%s': %w", module, err)
+		}
+	}
+	return level, modules, nil
+}
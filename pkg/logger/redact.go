@@ -0,0 +1,433 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces redacted values.
+const RedactedValue = "******"
+
+// DefaultRedactKeys are the key names redacted by default. A key is
+// sensitive when its lower-cased name contains one of them.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "authorization", "cookie",
+	"apikey", "api_key", "credential", "private_key", "dsn",
+}
+
+// redactPattern is a regular expression whose matches are passed to replace.
+type redactPattern struct {
+	re      *regexp.Regexp
+	replace func(match string) string
+	// mayMatch, if set, is a cheap check run before re: strings it rejects
+	// cannot contain a match
+	mayMatch func(s string) bool
+}
+
+// defaultPatterns match card numbers passing the Luhn check, email addresses,
+// bearer tokens and passwords in URLs.
+var defaultPatterns = []redactPattern{
+	{
+		// before the email pattern, which matches "pass@host" of the URL
+		re:       regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://[^\s/?#@:]*:[^\s/?#@]*@`),
+		replace:  redactURLPassword,
+		mayMatch: func(s string) bool { return strings.Contains(s, "://") },
+	},
+	{
+		re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		replace: func(match string) string {
+			if !luhnValid(match) {
+				return match
+			}
+			return RedactedValue
+		},
+		mayMatch: func(s string) bool { return countDigits(s, 13) },
+	},
+	{
+		re:       regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		replace:  redactAll,
+		mayMatch: func(s string) bool { return strings.IndexByte(s, '@') >= 0 },
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`),
+		replace: func(match string) string {
+			return match[:len("bearer")] + " " + RedactedValue
+		},
+		mayMatch: func(s string) bool { return containsFold(s, "bearer") },
+	},
+}
+
+// countDigits reports whether s has at least n digits.
+func countDigits(s string, n int) bool {
+	for i := 0; i < len(s) && n > 0; i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n--
+		}
+	}
+	return n == 0
+}
+
+// containsFold reports whether s contains the lower-case ASCII substr,
+// ignoring case, without allocating.
+func containsFold(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactAll(string) string {
+	return RedactedValue
+}
+
+// redactURLPassword redacts the password of "scheme://user:password@",
+// keeping the user name, e.g. "mongodb://app:******@".
+func redactURLPassword(match string) string {
+	userinfo := strings.Index(match, "://") + len("://")
+	password := userinfo + strings.Index(match[userinfo:], ":") + 1
+	return match[:password] + RedactedValue + "@"
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Redactable is implemented by values that know how to present themselves
+// in logs, e.g. a user returning a copy without its password hash. The
+// result replaces the value before any other redaction.
+type Redactable interface {
+	Redact() any
+}
+
+// RedactOptions configures a Redactor. It is the `redact` entry of the
+// `logger` section of the configuration file.
+type RedactOptions struct {
+	// Keys are additional sensitive key names, matched case-insensitively
+	// against attribute keys, JSON object keys and key=value pairs in text.
+	Keys []string `mapstructure:"keys" desc:"Additional sensitive key names; a key is redacted when its name contains one"`
+	// JSONPaths are dotted paths of JSON values to redact, where * matches
+	// any object key or array element, e.g. "user.ssn" or "items.*.card".
+	JSONPaths []string `mapstructure:"json_paths" desc:"Dotted paths of JSON values to redact, * matches any key or element"`
+	// Patterns are additional regular expressions whose matches are redacted.
+	Patterns []string `mapstructure:"patterns" desc:"Additional regular expressions whose matches are redacted"`
+	// DisableDefaults disables DefaultRedactKeys and the card number, email,
+	// bearer token and URL password patterns.
+	DisableDefaults bool `mapstructure:"disable_defaults" desc:"Disable the default keys and the card number, email, bearer token and URL password patterns"`
+}
+
+// Redactor removes sensitive data from log attributes, text and JSON.
+type Redactor struct {
+	keys     []string
+	paths    [][]string
+	patterns []redactPattern
+	// pairs matches key=value and "key": "value" pairs of sensitive keys in text
+	pairs *regexp.Regexp
+}
+
+// NewRedactor creates a Redactor.
+func NewRedactor(opts RedactOptions) (*Redactor, error) {
+	r := &Redactor{}
+	if !opts.DisableDefaults {
+		r.keys = append(r.keys, DefaultRedactKeys...)
+		r.patterns = append(r.patterns, defaultPatterns...)
+	}
+	for _, key := range opts.Keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+	for _, path := range opts.JSONPaths {
+		r.paths = append(r.paths, strings.Split(strings.TrimPrefix(path, "$."), "."))
+	}
+	for _, pattern := range opts.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, redactPattern{re: re, replace: redactAll})
+	}
+
+	r.compilePairs()
+	return r, nil
+}
+
+// compilePairs compiles the regular expression matching the pairs of the
+// sensitive keys.
+func (r *Redactor) compilePairs() {
+	r.pairs = nil
+	if len(r.keys) == 0 {
+		return
+	}
+	quoted := make([]string, len(r.keys))
+	for i, key := range r.keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	r.pairs = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*["'` + "`" + `]?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s&,;)]+)`)
+}
+
+// WithKeys returns a copy of the Redactor that also treats keys as sensitive.
+func (r *Redactor) WithKeys(keys ...string) *Redactor {
+	c := *r
+	c.keys = append([]string(nil), r.keys...)
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			c.keys = append(c.keys, key)
+		}
+	}
+	c.compilePairs()
+	return &c
+}
+
+// defaultRedactor is the Redactor of loggers without Config.Redactor.
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	r, _ := NewRedactor(RedactOptions{})
+	defaultRedactor.Store(r)
+}
+
+// GetRedactor returns the default Redactor, used by loggers created without
+// Config.Redactor and by the built-in middlewares and database loggers.
+func GetRedactor() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// SetRedactor replaces the default Redactor. Init calls it when
+// Config.Redactor is set.
+func SetRedactor(r *Redactor) {
+	if r != nil {
+		defaultRedactor.Store(r)
+	}
+}
+
+// IsSensitiveKey reports whether values of the key are redacted.
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(lower, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String redacts the values of sensitive key=value pairs and the matches of
+// the patterns in s. Patterns are skipped for strings that cannot match
+// them, e.g. the email pattern for strings without '@', so that most
+// messages are not run through every regular expression.
+func (r *Redactor) String(s string) string {
+	// patterns first, so that "Authorization: Bearer <token>" loses the
+	// token rather than only the "Bearer" scheme
+	for _, p := range r.patterns {
+		if p.mayMatch == nil || p.mayMatch(s) {
+			s = p.re.ReplaceAllStringFunc(s, p.replace)
+		}
+	}
+	// a pair needs a separator
+	if r.pairs != nil && strings.ContainsAny(s, ":=") {
+		s = r.pairs.ReplaceAllString(s, "${1}"+RedactedValue)
+	}
+	return s
+}
+
+// JSON redacts the values of sensitive keys and JSON paths in a JSON
+// document and applies String to its string values. Data that is not JSON
+// is redacted with String.
+func (r *Redactor) JSON(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return []byte(r.String(string(data)))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return []byte(r.String(string(data)))
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r.jsonValue(v, nil)); err != nil {
+		return []byte(r.String(string(data)))
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (r *Redactor) jsonValue(v any, path []string) any {
+	if r.matchesPath(path) {
+		return RedactedValue
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.IsSensitiveKey(key) {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = r.jsonValue(value, append(path[:len(path):len(path)], key))
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = r.jsonValue(value, append(path[:len(path):len(path)], "*"))
+		}
+		return v
+	case string:
+		return r.String(v)
+	default:
+		return v
+	}
+}
+
+// matchesPath reports whether a JSON path matches one of the configured
+// paths. Array elements are always written as *.
+func (r *Redactor) matchesPath(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if p[i] != "*" && path[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Value redacts a value logged under key: sensitive keys are replaced,
+// Redactable values are replaced by their Redact result and strings are
+// redacted with JSON.
+func (r *Redactor) Value(key string, v any) any {
+	if r.IsSensitiveKey(key) {
+		return RedactedValue
+	}
+	if redactable, ok := v.(Redactable); ok {
+		v = redactable.Redact()
+	}
+	switch v := v.(type) {
+	case string:
+		return string(r.JSON([]byte(v)))
+	case []byte:
+		return string(r.JSON(v))
+	case error:
+		return r.String(v.Error())
+	default:
+		return v
+	}
+}
+
+// Attr redacts an attribute, including the attributes of groups.
+func (r *Redactor) Attr(a slog.Attr) slog.Attr {
+	if r.IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		if redactable, ok := a.Value.Any().(Redactable); ok {
+			a.Value = slog.AnyValue(redactable.Redact())
+		}
+	}
+	a.Value = a.Value.Resolve()
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.String(a.Value.String()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = r.Attr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, r.String(err.Error()))
+		}
+	}
+	return a
+}
+
+// redactHandler redacts the message and attributes of records.
+type redactHandler struct {
+	next slog.Handler
+	// redactor is nil for the default Redactor
+	redactor *Redactor
+}
+
+func newRedactHandler(next slog.Handler, redactor *Redactor) *redactHandler {
+	return &redactHandler{next: next, redactor: redactor}
+}
+
+func (h *redactHandler) current() *Redactor {
+	if h.redactor != nil {
+		return h.redactor
+	}
+	return GetRedactor()
+}
+
+// Enabled implements slog.Handler.
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redactor := h.current()
+	redacted := slog.NewRecord(r.Time, r.Level, redactor.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactor.Attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactor := h.current()
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactor.Attr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup implements slog.Handler.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactorString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain text", in: "order 42 created", want: "order 42 created"},
+		{name: "card number", in: "paid with 4111 1111 1111 1111", want: "paid with ******"},
+		{name: "not a card number", in: "id 4111111111111112", want: "id 4111111111111112"},
+		{name: "email", in: "sent to alice@example.com", want: "sent to ******"},
+		{name: "bearer token", in: "sent bearer eyJhbGciOi.J9x", want: "sent bearer ******"},
+		{name: "authorization header", in: "Authorization: Bearer eyJhbGciOi.J9x", want: "Authorization: ****** ******"},
+		{name: "url password", in: "dial mongodb://app:s3cret@db:27017", want: "dial mongodb://app:******@db:27017"},
+		{name: "key value pair", in: "login user=bob password=hunter2 ok", want: "login user=bob password=****** ok"},
+		{name: "json pair", in: `{"api_key": "abc"}`, want: `{"api_key": ******}`},
+	}
+	r := GetRedactor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.in); got != tt.want {
+				t.Fatalf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorOptions(t *testing.T) {
+	r, err := NewRedactor(RedactOptions{
+		Keys:            []string{"SSN"},
+		JSONPaths:       []string{"user.phone", "items.*.card"},
+		Patterns:        []string{`\bORD-\d+\b`},
+		DisableDefaults: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	if got := r.String("order ORD-42 for alice@example.com, ssn=123"); got != "order ****** for alice@example.com, ssn=******" {
+		t.Fatalf("String() = %q", got)
+	}
+	got := string(r.JSON([]byte(`{"user":{"phone":"123","name":"bob"},"items":[{"card":"x"}],"password":"p"}`)))
+	want := `{"items":[{"card":"******"}],"password":"p","user":{"name":"bob","phone":"******"}}`
+	if got != want {
+		t.Fatalf("JSON() = %s, want %s", got, want)
+	}
+
+	if _, err := NewRedactor(RedactOptions{Patterns: []string{"("}}); err == nil {
+		t.Fatal("NewRedactor() error = nil, want an invalid pattern error")
+	}
+}
+
+type redactTestUser struct {
+	Name         string
+	PasswordHash string
+}
+
+func (u redactTestUser) Redact() any {
+	return redactTestUser{Name: u.Name}
+}
+
+func TestRedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(newRedactHandler(slog.NewTextHandler(&buf, nil), nil)).
+		With("token", "t0k3n")
+
+	logger.Info("mail alice@example.com",
+		slog.String("dsn", "root:pass@tcp(db)"),
+		slog.Any("user", redactTestUser{Name: "bob", PasswordHash: "h4sh"}),
+		slog.Any("error", errors.New("dial mongodb://app:s3cret@db")),
+		slog.Group("req", slog.String("authorization", "Bearer abc")),
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{"alice@example.com", "t0k3n", "root:pass", "h4sh", "s3cret", "Bearer abc"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("output %q contains %q", out, leaked)
+		}
+	}
+	if !strings.Contains(out, "Name:bob") {
+		t.Fatalf("output %q lost the fields of the Redact result", out)
+	}
+}
+
+func BenchmarkRedactorString(b *testing.B) {
+	inputs := map[string]string{
+		"short":     "ok",
+		"plain":     "GET /api/v1/orders/42 served in 12ms by order-service",
+		"sensitive": "user alice@example.com paid with 4111 1111 1111 1111, token=abc",
+	}
+	r := GetRedactor()
+	for name, in := range inputs {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				r.String(in)
+			}
+		})
+	}
+}
+
+func BenchmarkRedactHandler(b *testing.B) {
+	logger := slog.New(newRedactHandler(slog.NewTextHandler(io.Discard, nil), nil))
+	for b.Loop() {
+		logger.Info("order created", "order_id", "42", "status", "pending", "password", "hunter2")
+	}
+}
@@ -0,0 +1,347 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp of rotated file names, e.g.
+// "app-2024-05-01T10-00-00.000.log".
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions configures a RotatingFile.
+type RotateOptions struct {
+	// Filename is the file to write to. Rotated files are kept in the same
+	// directory, named <name>-<timestamp><ext>.
+	Filename string `mapstructure:"filename" validate:"required" desc:"Log file path; rotated files are kept next to it"`
+	// MaxSize is the size in megabytes at which the file is rotated; 0
+	// disables size-based rotation.
+	MaxSize int `mapstructure:"max_size" validate:"gte=0" desc:"Size in megabytes at which the file is rotated, 0 disables"`
+	// RotateInterval rotates the file when an interval boundary in local
+	// time is crossed, e.g. 24h at midnight; 0 disables time-based rotation.
+	RotateInterval time.Duration `mapstructure:"rotate_interval" desc:"Rotate when the interval boundary in local time is crossed, e.g. 24h at midnight"`
+	// MaxBackups is the number of rotated files to keep; 0 keeps all.
+	MaxBackups int `mapstructure:"max_backups" validate:"gte=0" desc:"Number of rotated files to keep, 0 keeps all"`
+	// MaxAge removes rotated files older than the duration; 0 keeps them.
+	MaxAge time.Duration `mapstructure:"max_age" desc:"Remove rotated files older than the duration, 0 keeps them"`
+	// Compress gzips rotated files.
+	Compress bool `mapstructure:"compress" desc:"Gzip rotated files"`
+}
+
+// RotatingFile is an io.WriteCloser writing to a file that is rotated by
+// size and time. Rotated files are compressed and removed in the background.
+type RotatingFile struct {
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	deadline time.Time
+
+	// millWG tracks the background compression and cleanup
+	millWG sync.WaitGroup
+	// millMu serializes compression and cleanup runs
+	millMu sync.Mutex
+}
+
+// NewRotatingFile opens opts.Filename for appending, creating it and its
+// directory if needed. An existing file from a previous interval is rotated
+// first.
+func NewRotatingFile(opts RotateOptions) (*RotatingFile, error) {
+	if opts.Filename == "" {
+		return nil, errors.New("log filename is empty")
+	}
+	if opts.MaxSize < 0 || opts.MaxBackups < 0 || opts.MaxAge < 0 || opts.RotateInterval < 0 {
+		return nil, errors.New("log rotation limits must not be negative")
+	}
+
+	f := &RotatingFile{opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer, rotating the file first when the write would
+// exceed MaxSize or the interval boundary has been crossed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	maxSize := int64(f.opts.MaxSize) * 1024 * 1024
+	if (maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > maxSize) ||
+		(!f.deadline.IsZero() && !time.Now().Before(f.deadline)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it with a timestamp and opens a
+// new file.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Sync commits the current file to stable storage.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for the background compression and
+// cleanup to finish.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.millWG.Wait()
+	return err
+}
+
+// open opens the file for appending.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.opts.Filename), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	info, err := os.Stat(f.opts.Filename)
+	switch {
+	case err == nil && f.opts.RotateInterval > 0 && !info.ModTime().Before(f.boundary(time.Now())):
+		// the file belongs to the current interval
+	case err == nil && f.opts.RotateInterval > 0:
+		if err := f.backup(info.ModTime()); err != nil {
+			return err
+		}
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	file, err := os.OpenFile(f.opts.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err = file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	if f.opts.RotateInterval > 0 {
+		f.deadline = f.boundary(time.Now()).Add(f.opts.RotateInterval)
+	}
+	return nil
+}
+
+// rotate renames the current file and opens a new one. f.mu must be held.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	if err := f.backup(time.Now()); err != nil {
+		// keep writing to the current file
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+	return f.open()
+}
+
+// backup renames the file to its backup name and starts the background
+// compression and cleanup.
+func (f *RotatingFile) backup(t time.Time) error {
+	dir, prefix, ext := f.nameParts()
+	name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+	// do not overwrite a file rotated in the same millisecond
+	for backupExists(name) {
+		t = t.Add(time.Millisecond)
+		name = filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+	}
+	if err := os.Rename(f.opts.Filename, name); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	f.millWG.Add(1)
+	go func() {
+		defer f.millWG.Done()
+		f.mill()
+	}()
+	return nil
+}
+
+// backupExists reports whether a rotated file, compressed or not, is named
+// name.
+func backupExists(name string) bool {
+	for _, path := range []string{name, name + ".gz"} {
+		if _, err := os.Lstat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mill compresses rotated files and removes the ones beyond MaxBackups or
+// older than MaxAge. Errors are ignored, the next rotation retries.
+func (f *RotatingFile) mill() {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups := f.backups()
+
+	var remove []backupFile
+	if f.opts.MaxBackups > 0 && len(backups) > f.opts.MaxBackups {
+		remove = backups[f.opts.MaxBackups:]
+		backups = backups[:f.opts.MaxBackups]
+	}
+	if f.opts.MaxAge > 0 {
+		cutoff := time.Now().Add(-f.opts.MaxAge)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.time.Before(cutoff) {
+				remove = append(remove, b)
+			} else {
+				kept = append(kept, b)
+			}
+		}
+		backups = kept
+	}
+
+	for _, b := range remove {
+		_ = os.Remove(b.path)
+	}
+
+	if f.opts.Compress {
+		for _, b := range backups {
+			if !strings.HasSuffix(b.path, ".gz") {
+				_ = compressFile(b.path)
+			}
+		}
+	}
+}
+
+// backupFile is a rotated file.
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups returns the rotated files, newest first.
+func (f *RotatingFile) backups() []backupFile {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var backups []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ext), time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups
+}
+
+// nameParts splits the file name into its directory, the backup name
+// prefix and the extension, e.g. "logs", "app-" and ".log".
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.opts.Filename)
+	base := filepath.Base(f.opts.Filename)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// boundary returns the start of the rotation interval containing t, aligned
+// in local time.
+func (f *RotatingFile) boundary(t time.Time) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(f.opts.RotateInterval).Add(-shift)
+}
+
+// compressFile gzips path into path.gz and removes path.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
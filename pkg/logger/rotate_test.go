@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRotatingFile(t *testing.T, opts RotateOptions) *RotatingFile {
+	t.Helper()
+	if opts.Filename == "" {
+		opts.Filename = filepath.Join(t.TempDir(), "app.log")
+	}
+	f, err := NewRotatingFile(opts)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func write(t *testing.T, f *RotatingFile, data []byte) {
+	t.Helper()
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err = io.ReadAll(gz); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return data
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	f := newTestRotatingFile(t, RotateOptions{MaxSize: 1})
+	first := bytes.Repeat([]byte("a"), 600*1024)
+	second := bytes.Repeat([]byte("b"), 600*1024)
+
+	write(t, f, first)
+	if got := len(f.backups()); got != 0 {
+		t.Fatalf("%d backups before reaching MaxSize, want 0", got)
+	}
+	write(t, f, second)
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups := f.backups()
+	if len(backups) != 1 {
+		t.Fatalf("%d backups after exceeding MaxSize, want 1", len(backups))
+	}
+	if !bytes.Equal(readFile(t, backups[0].path), first) {
+		t.Fatal("backup does not hold the records written before the rotation")
+	}
+	if !bytes.Equal(readFile(t, f.opts.Filename), second) {
+		t.Fatal("current file does not hold the records written after the rotation")
+	}
+}
+
+func TestRotatingFileRotatesByTime(t *testing.T) {
+	f := newTestRotatingFile(t, RotateOptions{RotateInterval: 100 * time.Millisecond})
+
+	write(t, f, []byte("first\n"))
+	time.Sleep(time.Until(f.deadline) + 10*time.Millisecond)
+	write(t, f, []byte("second\n"))
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups := f.backups()
+	if len(backups) != 1 {
+		t.Fatalf("%d backups after crossing the interval boundary, want 1", len(backups))
+	}
+	if got := string(readFile(t, backups[0].path)); got != "first\n" {
+		t.Fatalf("backup = %q, want %q", got, "first\n")
+	}
+	if got := string(readFile(t, f.opts.Filename)); got != "second\n" {
+		t.Fatalf("current file = %q, want %q", got, "second\n")
+	}
+}
+
+func TestRotatingFileRotatesFileOfPreviousInterval(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(filename, []byte("yesterday\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(filename, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTestRotatingFile(t, RotateOptions{Filename: filename, RotateInterval: time.Hour})
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups := f.backups()
+	if len(backups) != 1 || string(readFile(t, backups[0].path)) != "yesterday\n" {
+		t.Fatalf("backups = %v, want the file of the previous interval", backups)
+	}
+	if data := readFile(t, filename); len(data) != 0 {
+		t.Fatalf("current file = %q, want empty", data)
+	}
+}
+
+func TestRotatingFilePrunesByCount(t *testing.T) {
+	f := newTestRotatingFile(t, RotateOptions{MaxBackups: 2})
+	for i := range 4 {
+		write(t, f, []byte{byte('0' + i)})
+		if err := f.Rotate(); err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups := f.backups()
+	if len(backups) != 2 {
+		t.Fatalf("%d backups, want MaxBackups 2", len(backups))
+	}
+	// 保留最新的备份
+	if a, b := string(readFile(t, backups[0].path)), string(readFile(t, backups[1].path)); a != "3" || b != "2" {
+		t.Fatalf("kept backups %q and %q, want the newest 3 and 2", a, b)
+	}
+}
+
+func TestRotatingFilePrunesByAge(t *testing.T) {
+	f := newTestRotatingFile(t, RotateOptions{MaxAge: 24 * time.Hour})
+	dir, prefix, ext := f.nameParts()
+	old := filepath.Join(dir, prefix+time.Now().Add(-48*time.Hour).Format(backupTimeFormat)+ext)
+	if err := os.WriteFile(old, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	write(t, f, []byte("new"))
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("backup older than MaxAge was kept: %v", err)
+	}
+	if backups := f.backups(); len(backups) != 1 || string(readFile(t, backups[0].path)) != "new" {
+		t.Fatalf("backups = %v, want only the new backup", backups)
+	}
+}
+
+func TestRotatingFileCompressesBackups(t *testing.T) {
+	f := newTestRotatingFile(t, RotateOptions{Compress: true})
+	write(t, f, []byte("compressed\n"))
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	backups := f.backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0].path, ".gz") {
+		t.Fatalf("backups = %v, want one compressed backup", backups)
+	}
+	if got := string(readFile(t, backups[0].path)); got != "compressed\n" {
+		t.Fatalf("decompressed backup = %q, want %q", got, "compressed\n")
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// sampleCounters is the number of counters records are hashed into. Records
+// whose keys share a counter are sampled together.
+const sampleCounters = 4096
+
+// SampleOptions configures sampling: in every interval the first records
+// with the same message and level are logged, then one in every Thereafter.
+type SampleOptions struct {
+	// Interval is the period after which the counters are reset.
+	Interval time.Duration `mapstructure:"interval" default:"1s" validate:"gte=0" desc:"Period after which the sampling counters are reset"`
+	// First is the number of records with the same message and level logged
+	// in each interval; 0 disables sampling.
+	First int `mapstructure:"first" validate:"gte=0" desc:"Records with the same message and level logged in each interval, 0 disables sampling"`
+	// Thereafter logs one in every Thereafter records after First; 0 drops
+	// them all.
+	Thereafter int `mapstructure:"thereafter" validate:"gte=0" desc:"Log one in every thereafter records after first, 0 drops them"`
+}
+
+// Enabled reports whether the options enable sampling.
+func (o *SampleOptions) Enabled() bool {
+	return o != nil && o.First > 0
+}
+
+// sampleCounter counts the records of a key in the current interval.
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// inc increments the counter, resetting it first when the interval ended.
+func (c *sampleCounter) inc(now time.Time, interval time.Duration) uint64 {
+	t := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if t > resetAt && c.resetAt.CompareAndSwap(resetAt, t+int64(interval)) {
+		c.count.Store(1)
+		return 1
+	}
+	return c.count.Add(1)
+}
+
+// sampler holds the counters shared by a sample handler and the handlers
+// derived from it.
+type sampler struct {
+	opts     SampleOptions
+	counters [sampleCounters]sampleCounter
+	dropped  atomic.Uint64
+}
+
+// sample reports whether a record is logged.
+func (s *sampler) sample(r slog.Record) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r.Level.String()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(r.Message))
+
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	n := s.counters[h.Sum32()%sampleCounters].inc(now, s.opts.Interval)
+	first := uint64(s.opts.First)
+	if n <= first || (s.opts.Thereafter > 0 && (n-first)%uint64(s.opts.Thereafter) == 0) {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// SampleHandler is a slog.Handler that samples records keyed by message and
+// level, limiting the volume of repeated records such as a log line per
+// query. Loggers derived with With and WithGroup share the counters.
+type SampleHandler struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+// NewSampleHandler creates a handler that samples the records passed to
+// next. An interval of 0 defaults to one second.
+func NewSampleHandler(next slog.Handler, opts SampleOptions) *SampleHandler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return &SampleHandler{next: next, sampler: &sampler{opts: opts}}
+}
+
+// Dropped returns the number of records dropped by sampling.
+func (h *SampleHandler) Dropped() uint64 {
+	return h.sampler.dropped.Load()
+}
+
+// Enabled implements slog.Handler.
+func (h *SampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *SampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.sample(r) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SampleHandler{next: h.next.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup implements slog.Handler.
+func (h *SampleHandler) WithGroup(name string) slog.Handler {
+	return &SampleHandler{next: h.next.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSampleHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SampleOptions
+		wantWritten int
+	}{
+		{name: "first only", opts: SampleOptions{First: 3}, wantWritten: 3},
+		// 前 2 条之后每 3 条记录一条：第 5、8 条
+		{name: "thereafter", opts: SampleOptions{First: 2, Thereafter: 3}, wantWritten: 4},
+		{name: "thereafter one", opts: SampleOptions{First: 1, Thereafter: 1}, wantWritten: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := newRecordingHandler()
+			close(next.gate)
+			h := NewSampleHandler(next, tt.opts)
+
+			now := time.Now()
+			for range 10 {
+				if err := h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "query", 0)); err != nil {
+					t.Fatalf("Handle() error = %v", err)
+				}
+			}
+
+			if got := len(next.written()); got != tt.wantWritten {
+				t.Fatalf("%d records written, want %d", got, tt.wantWritten)
+			}
+			if got := h.Dropped(); got != uint64(10-tt.wantWritten) {
+				t.Fatalf("Dropped() = %d, want %d", got, 10-tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestSampleHandlerKeysAndInterval(t *testing.T) {
+	next := newRecordingHandler()
+	close(next.gate)
+	h := NewSampleHandler(next, SampleOptions{First: 1, Interval: time.Minute})
+	// 派生的 handler 共享计数
+	derived := h.WithAttrs([]slog.Attr{slog.String("k", "v")}).WithGroup("g")
+
+	now := time.Now()
+	records := []struct {
+		handler slog.Handler
+		at      time.Time
+		level   slog.Level
+		msg     string
+		written bool
+	}{
+		{handler: h, at: now, level: slog.LevelInfo, msg: "a", written: true},
+		{handler: derived, at: now, level: slog.LevelInfo, msg: "a"},
+		{handler: h, at: now, level: slog.LevelError, msg: "a", written: true},
+		{handler: h, at: now, level: slog.LevelInfo, msg: "b", written: true},
+		{handler: h, at: now.Add(30 * time.Second), level: slog.LevelInfo, msg: "a"},
+		{handler: derived, at: now.Add(2 * time.Minute), level: slog.LevelInfo, msg: "a", written: true},
+	}
+	for i, rec := range records {
+		before := len(next.written())
+		if err := rec.handler.Handle(context.Background(), slog.NewRecord(rec.at, rec.level, rec.msg, 0)); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if written := len(next.written()) > before; written != rec.written {
+			t.Fatalf("record %d (%s %q) written = %v, want %v", i, rec.level, rec.msg, written, rec.written)
+		}
+	}
+}
+
+func TestSampleOptionsEnabled(t *testing.T) {
+	tests := []struct {
+		opts *SampleOptions
+		want bool
+	}{
+		{opts: nil},
+		{opts: &SampleOptions{Thereafter: 10}},
+		{opts: &SampleOptions{First: 1}, want: true},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Enabled(); got != tt.want {
+			t.Fatalf("%+v.Enabled() = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
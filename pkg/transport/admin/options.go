@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"log/slog"
+
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/logger"
+	"github.com/yanking/gomicro/pkg/transport/rest"
+)
+
+// Option 定义管理服务器选项函数
+type Option func(*Server)
+
+// WithAddr 设置管理服务器监听地址，默认为 "127.0.0.1:9090"
+// 管理端点没有鉴权，监听其他网卡时该端口不得对外暴露
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithBuildInfo 设置应用名称和版本，通过 /buildinfo 端点返回
+func WithBuildInfo(appName, version string) Option {
+	return func(s *Server) {
+		s.appName = appName
+		s.version = version
+	}
+}
+
+// WithHealthRegistry 设置健康检查注册表，启用后注册 /livez 和 /readyz 端点
+func WithHealthRegistry(registry *health.Registry) Option {
+	return func(s *Server) {
+		s.healthRegistry = registry
+	}
+}
+
+// WithConfig 设置获取当前生效配置的函数，启用后注册 /config 端点
+// 返回的配置中名称包含 password、secret、token 等关键字的字段会被脱敏
+func WithConfig(config func() any) Option {
+	return func(s *Server) {
+		s.config = config
+	}
+}
+
+// WithRedactKeys 在日志默认脱敏器（logger.GetRedactor）的关键字之外添加需要脱敏的配置字段名称关键字，匹配时忽略大小写
+func WithRedactKeys(keys ...string) Option {
+	return func(s *Server) {
+		s.redactKeys = append(s.redactKeys, keys...)
+	}
+}
+
+// WithComponents 设置获取组件状态的函数，启用后注册 /components 端点
+func WithComponents(components func() []ComponentStatus) Option {
+	return func(s *Server) {
+		s.components = components
+	}
+}
+
+// WithLevelVar 设置可在运行时调整的日志级别，启用后注册 /loglevel 端点
+// 通常与 logger.Config 的 LevelVar 字段使用同一个实例
+func WithLevelVar(level *slog.LevelVar) Option {
+	return func(s *Server) {
+		s.levelVar = level
+	}
+}
+
+// WithLevels 设置日志级别和按模块的日志级别，启用后注册 /loglevel 端点，支持按模块调整级别
+// 通常传入 logger.GetLevels() 或 logger.Config 的 Levels 字段，同时设置 WithLevelVar 时以该选项为准
+func WithLevels(levels *logger.Levels) Option {
+	return func(s *Server) {
+		s.levels = levels
+	}
+}
+
+// WithServerOptions 设置底层 HTTP 服务器的选项，例如读写超时时间
+func WithServerOptions(opts ...rest.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOpts = append(s.serverOpts, opts...)
+	}
+}
@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+// redact 将配置转换为可以序列化为 JSON 的通用结构，并使用 redactor 脱敏：
+// 名称敏感的字段被替换为 logger.RedactedValue，字符串值中的敏感内容（例如 URL 中的密码）被替换
+// 结构体字段名称使用 mapstructure 标签，与配置文件中的键名保持一致
+func redact(v any, redactor *logger.Redactor) any {
+	return redactValue(reflect.ValueOf(v), redactor)
+}
+
+func redactValue(v reflect.Value, redactor *logger.Redactor) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok && v.Kind() != reflect.Pointer {
+			if text, err := m.MarshalText(); err == nil {
+				return redactor.String(string(text))
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), redactor)
+	case reflect.Struct:
+		out := make(map[string]any)
+		redactStruct(v, redactor, out)
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := toString(iter.Key())
+			if redactor.IsSensitiveKey(key) {
+				out[key] = logger.RedactedValue
+				continue
+			}
+			out[key] = redactValue(iter.Value(), redactor)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), redactor)
+		}
+		return out
+	case reflect.String:
+		return redactor.String(v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+}
+
+// redactStruct 将结构体的导出字段写入 out，内嵌结构体和带 squash 标签的字段会被展开
+func redactStruct(v reflect.Value, redactor *logger.Redactor, out map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, squash := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if squash || (field.Anonymous && name == "") {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				redactStruct(fv, redactor, out)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if redactor.IsSensitiveKey(name) {
+			out[name] = logger.RedactedValue
+			continue
+		}
+		out[name] = redactValue(fv, redactor)
+	}
+}
+
+// fieldName 返回字段的 mapstructure 标签名称以及是否需要展开
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(opts, "squash")
+}
+
+func toString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
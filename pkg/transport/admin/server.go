@@ -0,0 +1,244 @@
+// Package admin provides an administration and diagnostics HTTP server.
+// It runs on its own address, separate from the public HTTP server, and exposes
+// pprof, health probes, build information, the effective configuration with
+// secrets redacted, component states, runtime log-level switching and goroutine dumps.
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yanking/gomicro/pkg/health"
+	"github.com/yanking/gomicro/pkg/lifecycle"
+	"github.com/yanking/gomicro/pkg/logger"
+	"github.com/yanking/gomicro/pkg/transport/rest"
+	restpprof "github.com/yanking/gomicro/pkg/transport/rest/pprof"
+)
+
+// ComponentStatus 表示一个组件的运行状态
+type ComponentStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Since     time.Time `json:"since"`
+	Restarts  int       `json:"restarts"`
+	DependsOn []string  `json:"depends_on,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BuildInfo 是 /buildinfo 端点返回的构建信息
+type BuildInfo struct {
+	AppName   string            `json:"app_name"`
+	Version   string            `json:"version"`
+	GoVersion string            `json:"go_version"`
+	Module    string            `json:"module,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+	Hostname  string            `json:"hostname"`
+	PID       int               `json:"pid"`
+	StartedAt time.Time         `json:"started_at"`
+	Uptime    string            `json:"uptime"`
+}
+
+// Server 是管理和诊断服务器，实现了 lifecycle.Component 接口
+// 管理端点没有鉴权，只应在内网暴露，不应与对外提供服务的端口共用；默认只监听本机回环地址
+type Server struct {
+	rest       *rest.Server
+	addr       string
+	serverOpts []rest.ServerOption
+
+	appName        string
+	version        string
+	startedAt      time.Time
+	healthRegistry *health.Registry
+	config         func() any
+	redactKeys     []string
+	components     func() []ComponentStatus
+	levelVar       *slog.LevelVar
+	levels         *logger.Levels
+
+	logger *slog.Logger
+}
+
+// NewServer 创建一个新的管理服务器实例
+func NewServer(logger *slog.Logger, opts ...Option) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Server{
+		addr:      "127.0.0.1:9090",
+		startedAt: time.Now(),
+		logger:    logger.With(slog.String("component", "admin")),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	restOpts := append([]rest.ServerOption{rest.WithAddr(s.addr)}, s.serverOpts...)
+	if s.healthRegistry != nil {
+		restOpts = append(restOpts, rest.WithHealthRegistry(s.healthRegistry))
+	}
+	s.rest = rest.NewServer(s.logger, restOpts...)
+	s.registerRoutes()
+
+	return s
+}
+
+// registerRoutes 注册管理端点
+func (s *Server) registerRoutes() {
+	restpprof.Register(s.rest.Engine)
+
+	s.rest.GET("/debug/goroutines", s.goroutines)
+	s.rest.GET("/buildinfo", s.buildInfo)
+
+	if s.config != nil {
+		s.rest.GET("/config", s.effectiveConfig)
+	}
+	if s.components != nil {
+		s.rest.GET("/components", s.componentStates)
+	}
+	if s.levels != nil {
+		handler := gin.WrapH(logger.LevelHandler(s.levels))
+		s.rest.GET("/loglevel", handler)
+		s.rest.PUT("/loglevel", s.logLevelsChanged(handler))
+		s.rest.DELETE("/loglevel", s.logLevelsChanged(handler))
+	} else if s.levelVar != nil {
+		s.rest.GET("/loglevel", s.getLogLevel)
+		s.rest.PUT("/loglevel", s.setLogLevel)
+	}
+}
+
+// Start 启动管理服务器
+func (s *Server) Start(ctx context.Context) error {
+	s.logger.Info("Starting admin server", slog.String("addr", s.addr))
+	return s.rest.Start(ctx)
+}
+
+// Stop 停止管理服务器
+func (s *Server) Stop(ctx context.Context) error {
+	return s.rest.Stop(ctx)
+}
+
+// Name 返回组件名称
+func (s *Server) Name() string {
+	return "admin_server"
+}
+
+// LongRunning 声明 Start 会阻塞直至服务器停止
+func (s *Server) LongRunning() bool {
+	return true
+}
+
+// goroutines 返回所有 goroutine 的堆栈信息，debug 参数与 pprof 相同，默认为 2
+func (s *Server) goroutines(c *gin.Context) {
+	level, err := strconv.Atoi(c.DefaultQuery("debug", "2"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid debug level"})
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := pprof.Lookup("goroutine").WriteTo(c.Writer, level); err != nil {
+		s.logger.Error("Failed to write goroutine dump", slog.Any("error", err))
+	}
+}
+
+// buildInfo 返回应用的构建信息
+func (s *Server) buildInfo(c *gin.Context) {
+	hostname, _ := os.Hostname()
+	info := BuildInfo{
+		AppName:   s.appName,
+		Version:   s.version,
+		GoVersion: runtime.Version(),
+		Hostname:  hostname,
+		PID:       os.Getpid(),
+		StartedAt: s.startedAt,
+		Uptime:    time.Since(s.startedAt).Round(time.Second).String(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module = bi.Main.Path
+		info.Settings = make(map[string]string)
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision", "vcs.time", "vcs.modified", "GOOS", "GOARCH":
+				info.Settings[setting.Key] = setting.Value
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// effectiveConfig 返回使用日志默认脱敏器和 WithRedactKeys 添加的关键字脱敏后的当前生效配置
+func (s *Server) effectiveConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, redact(s.config(), logger.GetRedactor().WithKeys(s.redactKeys...)))
+}
+
+// componentStates 返回所有组件的运行状态
+func (s *Server) componentStates(c *gin.Context) {
+	c.JSON(http.StatusOK, s.components())
+}
+
+// getLogLevel 返回当前的日志级别
+func (s *Server) getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": s.levelVar.Level().String()})
+}
+
+// setLogLevel 修改日志级别，级别可以通过 level 查询参数或 JSON 请求体 {"level": "debug"} 指定
+func (s *Server) setLogLevel(c *gin.Context) {
+	text := c.Query("level")
+	if text == "" {
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "level is required"})
+			return
+		}
+		text = req.Level
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	old := s.levelVar.Level()
+	s.levelVar.Set(level)
+	s.logger.Info("Log level changed",
+		slog.String("from", old.String()),
+		slog.String("to", level.String()),
+	)
+	c.JSON(http.StatusOK, gin.H{"level": level.String()})
+}
+
+// logLevelsChanged 在日志级别修改成功后记录日志
+func (s *Server) logLevelsChanged(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler(c)
+		if c.Writer.Status() == http.StatusOK {
+			s.logger.Info("Log levels changed",
+				slog.String("level", s.levels.Level().String()),
+				slog.Any("modules", s.levels.ModuleLevels()),
+			)
+		}
+	}
+}
+
+// Ensure Server implements lifecycle.Component and lifecycle.LongRunning interfaces
+var (
+	_ lifecycle.Component   = (*Server)(nil)
+	_ lifecycle.LongRunning = (*Server)(nil)
+)
@@ -4,7 +4,6 @@ package middlewares
 
 import (
 	"bytes"
-	"context"
 	"io"
 	"log/slog"
 	"time"
@@ -12,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/yanking/gomicro/pkg/constants"
+	"github.com/yanking/gomicro/pkg/logger"
 )
 
 // RequestContextKey 定义request_id在context中的键类型
@@ -23,23 +23,28 @@ const (
 )
 
 // Context 创建一个上下文中间件，用于日志记录和请求追踪
-func Context(logger *slog.Logger) gin.HandlerFunc {
+func Context(log *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 检查请求中是否已存在request_id，如果不存在则生成新的UUID
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(constants.RequestIDHeader)
 		if requestID == "" {
 			requestID = uuid.NewString()
 		}
 
-		// 将request_id放入context中，便于后续处理使用
-		ctx := context.WithValue(c.Request.Context(), constants.RequestIDCtx{}, requestID)
+		// 将request_id和W3C traceparent中的追踪信息放入context中，
+		// 通过 *Context 方法记录的日志会自动带上这些信息
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		ctx = logger.WithTraceparent(ctx, c.GetHeader("traceparent"))
 		c.Request = c.Request.WithContext(ctx)
 
+		// 请求体、响应体和查询参数中的敏感数据在记录前脱敏
+		redactor := logger.GetRedactor()
+
 		// 创建带请求信息的日志记录器
-		ctxLogger := logger.With(
+		ctxLogger := log.With(
 			constants.RequestIDKey, requestID,
 			"path", c.Request.Method+"|"+c.Request.URL.Path,
-			"query", c.Request.URL.RawQuery,
+			"query", redactor.String(c.Request.URL.RawQuery),
 		)
 
 		// 读取并记录请求体（如果有的话）
@@ -47,14 +52,14 @@ func Context(logger *slog.Logger) gin.HandlerFunc {
 		if c.Request.Body != nil {
 			reqBytes, _ := io.ReadAll(c.Request.Body)
 			if len(reqBytes) > 0 {
-				reqBody = string(reqBytes)
+				reqBody = string(redactor.JSON(reqBytes))
 				// 恢复请求体，以便后续处理可以读取
 				c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBytes))
 			}
 		}
 
 		// 记录请求信息
-		ctxLogger.Info("Request received",
+		ctxLogger.InfoContext(ctx, "Request received",
 			slog.String("body", reqBody),
 			slog.String("client_ip", c.ClientIP()),
 			slog.String("user_agent", c.Request.UserAgent()),
@@ -77,8 +82,8 @@ func Context(logger *slog.Logger) gin.HandlerFunc {
 			errors[i] = err.Error()
 		}
 
-		ctxLogger.Info("Response sent",
-			slog.String("body", c.Writer.(*responseWriter).body.String()),
+		ctxLogger.InfoContext(ctx, "Response sent",
+			slog.String("body", string(redactor.JSON(c.Writer.(*responseWriter).body.Bytes()))),
 			slog.Int("status", c.Writer.Status()),
 			slog.Duration("latency", latency),
 			slog.Any("errors", errors),
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/yanking/gomicro/pkg/feature"
+)
+
+// Feature 创建功能开关中间件，为每个请求创建求值器并放入请求的 context 中，
+// 处理函数通过 feature.Enabled(c.Request.Context(), "name") 判断开关。
+// extract 为 nil 时从 X-User-ID、X-Tenant-ID 请求头中提取用户和租户
+func Feature(provider feature.Provider, extract func(c *gin.Context) feature.Attributes) gin.HandlerFunc {
+	if extract == nil {
+		extract = func(c *gin.Context) feature.Attributes {
+			return feature.AttributesFromHeader(c.Request.Header)
+		}
+	}
+
+	return func(c *gin.Context) {
+		evaluator := feature.NewEvaluator(provider, extract(c))
+		c.Request = c.Request.WithContext(feature.NewContext(c.Request.Context(), evaluator))
+		c.Next()
+	}
+}
@@ -2,6 +2,8 @@ package rest
 
 import (
 	"time"
+
+	"github.com/yanking/gomicro/pkg/health"
 )
 
 // ServerOption 定义HTTP服务器选项函数
@@ -63,6 +65,13 @@ func WithEnableProfiling(profiling bool) ServerOption {
 	}
 }
 
+// WithShutdownTimeout 设置优雅关闭的超时时间，默认为 0，即只受 Stop 传入的上下文限制
+func WithShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
 // WithHealthz 启用/禁用健康检查端点
 func WithHealthz(healthz bool) ServerOption {
 	return func(s *Server) {
@@ -70,6 +79,13 @@ func WithHealthz(healthz bool) ServerOption {
 	}
 }
 
+// WithHealthRegistry 设置健康检查注册表，启用后注册 /livez 和 /readyz 端点
+func WithHealthRegistry(registry *health.Registry) ServerOption {
+	return func(s *Server) {
+		s.healthRegistry = registry
+	}
+}
+
 // WithMetrics 启用/禁用指标收集
 func WithMetrics(enable bool) ServerOption {
 	return func(s *Server) {
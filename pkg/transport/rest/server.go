@@ -8,12 +8,14 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
+	"github.com/yanking/gomicro/pkg/health"
 )
 
 // Server 代表HTTP服务器
@@ -28,7 +30,12 @@ type Server struct {
 	trustedProxies []string
 	server         *http.Server
 
+	shutdownTimeout time.Duration
+	// draining 表示服务器正在下线，健康检查将返回 503
+	draining atomic.Bool
+
 	healthz         bool
+	healthRegistry  *health.Registry
 	enableProfiling bool
 	enableMetrics   bool
 
@@ -56,6 +63,7 @@ func NewServer(logger *slog.Logger, opts ...ServerOption) *Server {
 		healthz:         true,
 		enableProfiling: true,
 		enableMetrics:   true,
+		transName:       "zh",
 		logger:          logger,
 	}
 
@@ -71,10 +79,21 @@ func NewServer(logger *slog.Logger, opts ...ServerOption) *Server {
 	// 注册健康检查路由
 	if srv.healthz {
 		srv.Engine.GET("/healthz", func(c *gin.Context) {
+			if srv.draining.Load() {
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status": "draining",
+				})
+				return
+			}
 			c.JSON(http.StatusOK, gin.H{
 				"status": "ok",
 			})
 		})
+
+		if srv.healthRegistry != nil {
+			srv.Engine.GET("/livez", srv.livez)
+			srv.Engine.GET("/readyz", srv.readyz)
+		}
 	}
 
 	return srv
@@ -126,9 +145,13 @@ func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping HTTP server")
 
 	if s.server != nil {
-		// 创建一个带超时的上下文，确保服务器能及时关闭
-		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
+		// 如果配置了关闭超时时间，在调用方上下文的基础上再加以限制
+		shutdownCtx := ctx
+		if s.shutdownTimeout > 0 {
+			var cancel context.CancelFunc
+			shutdownCtx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+			defer cancel()
+		}
 
 		if err := s.server.Shutdown(shutdownCtx); err != nil {
 			s.logger.Error("HTTP server shutdown error", slog.Any("error", err))
@@ -150,6 +173,40 @@ func (s *Server) Name() string {
 	return "gin_rest_server"
 }
 
+// Drain 将服务器标记为正在下线，健康检查端点随后返回 503
+func (s *Server) Drain() {
+	s.draining.Store(true)
+	s.logger.Info("HTTP server is draining")
+}
+
+// LongRunning 声明 Start 会阻塞直至服务器停止
+func (s *Server) LongRunning() bool {
+	return true
+}
+
+// livez 返回存活检查结果
+func (s *Server) livez(c *gin.Context) {
+	report := s.healthRegistry.Liveness(c.Request.Context())
+	c.JSON(probeStatusCode(report.Up()), report)
+}
+
+// readyz 返回就绪检查结果，服务器下线期间始终返回 503
+func (s *Server) readyz(c *gin.Context) {
+	report := s.healthRegistry.Readiness(c.Request.Context())
+	if s.draining.Load() {
+		report.Status = health.StatusDown
+	}
+	c.JSON(probeStatusCode(report.Up()), report)
+}
+
+// probeStatusCode 根据检查结果返回 HTTP 状态码
+func probeStatusCode(up bool) int {
+	if up {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
 // initTrans 初始化翻译器
 func (s *Server) initTrans(locale string) error {
 	// 创建本地化翻译器
@@ -8,15 +8,17 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
+
+	"github.com/yanking/gomicro/pkg/logger"
 )
 
 // LoggingInterceptor returns a new unary client interceptor that logs outgoing requests.
-func LoggingInterceptor(logger *slog.Logger) grpc.UnaryClientInterceptor {
+func LoggingInterceptor(log *slog.Logger) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{},
 		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		startTime := time.Now()
 
-		logger.Info("gRPC client request started",
+		log.InfoContext(ctx, "gRPC client request started",
 			slog.String("method", method),
 			slog.String("target", cc.Target()),
 			slog.String("start_time", startTime.Format(time.RFC3339)),
@@ -27,15 +29,15 @@ func LoggingInterceptor(logger *slog.Logger) grpc.UnaryClientInterceptor {
 		duration := time.Since(startTime)
 		if err != nil {
 			st, _ := status.FromError(err)
-			logger.Error("gRPC client request failed",
+			log.ErrorContext(ctx, "gRPC client request failed",
 				slog.String("method", method),
 				slog.String("target", cc.Target()),
 				slog.String("duration", duration.String()),
-				slog.String("error", err.Error()),
+				slog.String("error", logger.GetRedactor().String(err.Error())),
 				slog.String("code", st.Code().String()),
 			)
 		} else {
-			logger.Info("gRPC client request completed",
+			log.InfoContext(ctx, "gRPC client request completed",
 				slog.String("method", method),
 				slog.String("target", cc.Target()),
 				slog.String("duration", duration.String()),
@@ -47,12 +49,12 @@ func LoggingInterceptor(logger *slog.Logger) grpc.UnaryClientInterceptor {
 }
 
 // LoggingStreamInterceptor returns a new stream client interceptor that logs outgoing streams.
-func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamClientInterceptor {
+func LoggingStreamInterceptor(log *slog.Logger) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
 		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		startTime := time.Now()
 
-		logger.Info("gRPC client stream started",
+		log.InfoContext(ctx, "gRPC client stream started",
 			slog.String("method", method),
 			slog.String("target", cc.Target()),
 			slog.String("start_time", startTime.Format(time.RFC3339)),
@@ -63,15 +65,15 @@ func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamClientInterceptor
 		duration := time.Since(startTime)
 		if err != nil {
 			st, _ := status.FromError(err)
-			logger.Error("gRPC client stream failed",
+			log.ErrorContext(ctx, "gRPC client stream failed",
 				slog.String("method", method),
 				slog.String("target", cc.Target()),
 				slog.String("duration", duration.String()),
-				slog.String("error", err.Error()),
+				slog.String("error", logger.GetRedactor().String(err.Error())),
 				slog.String("code", st.Code().String()),
 			)
 		} else {
-			logger.Info("gRPC client stream created",
+			log.InfoContext(ctx, "gRPC client stream created",
 				slog.String("method", method),
 				slog.String("target", cc.Target()),
 				slog.String("duration", duration.String()),
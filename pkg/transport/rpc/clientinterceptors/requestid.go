@@ -0,0 +1,43 @@
+package clientinterceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yanking/gomicro/pkg/constants"
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+// RequestIDInterceptor returns a new unary client interceptor that propagates the request id
+// of the context in the x-request-id metadata, so that the server logs the same request id.
+func RequestIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withOutgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDStreamInterceptor returns a new stream client interceptor that propagates the
+// request id of the context in the x-request-id metadata.
+func RequestIDStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withOutgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// withOutgoingRequestID appends the request id of ctx to the outgoing metadata.
+func withOutgoingRequestID(ctx context.Context) context.Context {
+	requestID := logger.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	key := strings.ToLower(constants.RequestIDHeader)
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, key, requestID)
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultHealthSyncInterval 是同步健康检查状态的默认间隔
+const defaultHealthSyncInterval = 5 * time.Second
+
+// syncHealth 定期根据健康检查注册表的结果更新 gRPC 健康检查服务的状态，直到服务器停止
+func (s *Server) syncHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.healthSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		s.updateHealth(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-s.healthStop:
+			return
+		}
+	}
+}
+
+// updateHealth 更新整体及每个已注册服务的健康状态
+func (s *Server) updateHealth(ctx context.Context) {
+	report := s.healthRegistry.Readiness(ctx)
+	s.healthServer.SetServingStatus("", servingStatus(report.Up()))
+
+	services := make(map[string]struct{})
+	for service := range s.Server.GetServiceInfo() {
+		if service == healthpb.Health_ServiceDesc.ServiceName || strings.HasPrefix(service, "grpc.reflection.") {
+			continue
+		}
+		services[service] = struct{}{}
+	}
+	for service := range s.healthServices {
+		services[service] = struct{}{}
+	}
+
+	for service := range services {
+		up := report.Up()
+		if checks, ok := s.healthServices[service]; ok {
+			serviceReport, err := s.healthRegistry.Check(ctx, checks...)
+			if err != nil {
+				s.logger.Warn("Invalid health checks for service",
+					slog.String("service", service),
+					slog.Any("error", err),
+				)
+				up = false
+			} else {
+				up = serviceReport.Up() && !s.healthRegistry.Draining()
+			}
+		}
+		s.healthServer.SetServingStatus(service, servingStatus(up))
+	}
+}
+
+// servingStatus 将检查结果转换为 gRPC 健康状态
+func servingStatus(up bool) healthpb.HealthCheckResponse_ServingStatus {
+	if up {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
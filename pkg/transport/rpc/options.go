@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"time"
 
+	"github.com/yanking/gomicro/pkg/health"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
@@ -60,6 +61,35 @@ func WithHealthz(enabled bool) ServerOption {
 	}
 }
 
+// WithShutdownTimeout 设置优雅关闭的超时时间，超时后强制停止，默认为 0，即只受 Stop 传入的上下文限制
+func WithShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// WithHealthRegistry 设置健康检查注册表，gRPC 健康检查服务的状态将由注册表的就绪检查结果驱动
+func WithHealthRegistry(registry *health.Registry) ServerOption {
+	return func(s *Server) {
+		s.healthRegistry = registry
+	}
+}
+
+// WithHealthService 指定 gRPC 服务的健康状态由哪些检查项决定
+// 未指定的服务使用所有检查项的就绪检查结果
+func WithHealthService(service string, checks ...string) ServerOption {
+	return func(s *Server) {
+		s.healthServices[service] = checks
+	}
+}
+
+// WithHealthSyncInterval 设置同步健康检查状态的间隔，默认 5 秒
+func WithHealthSyncInterval(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		s.healthSyncInterval = interval
+	}
+}
+
 // WithReflection 启用/禁用gRPC反射
 func WithReflection(enabled bool) ServerOption {
 	return func(s *Server) {
@@ -9,8 +9,10 @@ import (
 	"errors"
 	"log/slog"
 	"net"
+	"sync"
 	"time"
 
+	healthcheck "github.com/yanking/gomicro/pkg/health"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
@@ -29,6 +31,13 @@ type Server struct {
 	streamInterceptors []grpc.StreamServerInterceptor
 	logger             *slog.Logger
 	healthServer       *health.Server
+	shutdownTimeout    time.Duration
+
+	healthRegistry     *healthcheck.Registry
+	healthServices     map[string][]string
+	healthSyncInterval time.Duration
+	healthStop         chan struct{}
+	healthStopOnce     sync.Once
 }
 
 // NewServer 创建一个新的gRPC服务器实例
@@ -39,6 +48,10 @@ func NewServer(logger *slog.Logger, opts ...ServerOption) *Server {
 		enableReflection: true,
 		logger:           logger,
 		healthServer:     health.NewServer(),
+
+		healthServices:     make(map[string][]string),
+		healthSyncInterval: defaultHealthSyncInterval,
+		healthStop:         make(chan struct{}),
 	}
 
 	// 应用选项
@@ -82,7 +95,7 @@ func NewServer(logger *slog.Logger, opts ...ServerOption) *Server {
 }
 
 // Start 启动gRPC服务器
-func (s *Server) Start(_ context.Context) error {
+func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("Starting gRPC server", slog.String("addr", s.addr))
 
 	// 监听TCP端口
@@ -92,6 +105,11 @@ func (s *Server) Start(_ context.Context) error {
 		return err
 	}
 
+	// 根据健康检查注册表同步健康状态
+	if s.healthz && s.healthRegistry != nil {
+		go s.syncHealth(ctx)
+	}
+
 	// 启动服务器
 	if err := s.Server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
 		s.logger.Error("gRPC server error", slog.Any("error", err))
@@ -104,15 +122,20 @@ func (s *Server) Start(_ context.Context) error {
 // Stop 停止gRPC服务器
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping gRPC server")
+	s.healthStopOnce.Do(func() { close(s.healthStop) })
 
 	// 通知健康检查服务服务器正在关闭
 	if s.healthServer != nil {
 		s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
 	}
 
-	// 创建一个带超时的上下文，确保服务器能及时关闭
-	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	// 如果配置了关闭超时时间，在调用方上下文的基础上再加以限制
+	shutdownCtx := ctx
+	if s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
 
 	// 优雅地停止服务器
 	done := make(chan struct{})
@@ -137,6 +160,19 @@ func (s *Server) Name() string {
 	return "grpc_server"
 }
 
+// Drain 将所有服务的健康状态置为 NOT_SERVING，以便负载均衡器摘除流量
+func (s *Server) Drain() {
+	if s.healthServer != nil {
+		s.healthServer.Shutdown()
+	}
+	s.logger.Info("gRPC server is draining")
+}
+
+// LongRunning 声明 Start 会阻塞直至服务器停止
+func (s *Server) LongRunning() bool {
+	return true
+}
+
 // GetHealthServer 返回健康检查服务器实例
 func (s *Server) GetHealthServer() *health.Server {
 	return s.healthServer
@@ -0,0 +1,57 @@
+package serverinterceptors
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yanking/gomicro/pkg/feature"
+)
+
+// FeatureExtractor extracts feature flag attributes from an incoming request context.
+type FeatureExtractor func(ctx context.Context) feature.Attributes
+
+// FeatureInterceptor returns a new unary server interceptor that attaches a feature flag
+// evaluator to the request context. A nil extract reads the user and tenant from the
+// x-user-id and x-tenant-id metadata.
+func FeatureInterceptor(provider feature.Provider, extract FeatureExtractor) grpc.UnaryServerInterceptor {
+	if extract == nil {
+		extract = attributesFromMetadata
+	}
+
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		evaluator := feature.NewEvaluator(provider, extract(ctx))
+		return handler(feature.NewContext(ctx, evaluator), req)
+	}
+}
+
+// FeatureStreamInterceptor returns a new stream server interceptor that attaches a feature
+// flag evaluator to the stream context.
+func FeatureStreamInterceptor(provider feature.Provider, extract FeatureExtractor) grpc.StreamServerInterceptor {
+	if extract == nil {
+		extract = attributesFromMetadata
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		evaluator := feature.NewEvaluator(provider, extract(ctx))
+		return handler(srv, &contextStream{ServerStream: ss, ctx: feature.NewContext(ctx, evaluator)})
+	}
+}
+
+// attributesFromMetadata converts the incoming metadata into a header so that
+// "header:<Name>" rules match both HTTP and gRPC requests.
+func attributesFromMetadata(ctx context.Context) feature.Attributes {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return feature.AttributesFromHeader(header)
+}
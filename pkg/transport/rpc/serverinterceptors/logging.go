@@ -8,15 +8,17 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
+
+	"github.com/yanking/gomicro/pkg/logger"
 )
 
 // LoggingInterceptor returns a new unary server interceptor that logs incoming requests.
-func LoggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+func LoggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
 
-		logger.Info("gRPC request started",
+		log.InfoContext(ctx, "gRPC request started",
 			slog.String("method", info.FullMethod),
 			slog.String("start_time", startTime.Format(time.RFC3339)),
 		)
@@ -26,14 +28,14 @@ func LoggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
 		duration := time.Since(startTime)
 		if err != nil {
 			st, _ := status.FromError(err)
-			logger.Error("gRPC request failed",
+			log.ErrorContext(ctx, "gRPC request failed",
 				slog.String("method", info.FullMethod),
 				slog.String("duration", duration.String()),
-				slog.String("error", err.Error()),
+				slog.String("error", logger.GetRedactor().String(err.Error())),
 				slog.String("code", st.Code().String()),
 			)
 		} else {
-			logger.Info("gRPC request completed",
+			log.InfoContext(ctx, "gRPC request completed",
 				slog.String("method", info.FullMethod),
 				slog.String("duration", duration.String()),
 			)
@@ -44,12 +46,13 @@ func LoggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
 }
 
 // LoggingStreamInterceptor returns a new stream server interceptor that logs incoming streams.
-func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+func LoggingStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler) error {
+		ctx := ss.Context()
 		startTime := time.Now()
 
-		logger.Info("gRPC stream started",
+		log.InfoContext(ctx, "gRPC stream started",
 			slog.String("method", info.FullMethod),
 			slog.String("start_time", startTime.Format(time.RFC3339)),
 		)
@@ -59,14 +62,14 @@ func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor
 		duration := time.Since(startTime)
 		if err != nil {
 			st, _ := status.FromError(err)
-			logger.Error("gRPC stream failed",
+			log.ErrorContext(ctx, "gRPC stream failed",
 				slog.String("method", info.FullMethod),
 				slog.String("duration", duration.String()),
-				slog.String("error", err.Error()),
+				slog.String("error", logger.GetRedactor().String(err.Error())),
 				slog.String("code", st.Code().String()),
 			)
 		} else {
-			logger.Info("gRPC stream completed",
+			log.InfoContext(ctx, "gRPC stream completed",
 				slog.String("method", info.FullMethod),
 				slog.String("duration", duration.String()),
 			)
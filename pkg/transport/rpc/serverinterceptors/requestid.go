@@ -0,0 +1,70 @@
+package serverinterceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yanking/gomicro/pkg/constants"
+	"github.com/yanking/gomicro/pkg/logger"
+)
+
+// traceparentKey is the metadata key of the W3C trace context.
+const traceparentKey = "traceparent"
+
+// RequestIDInterceptor returns a new unary server interceptor that stores the request id of
+// the x-request-id metadata, or a new one, and the trace of the traceparent metadata in the
+// request context, so that loggers created by pkg/logger add them to every *Context log call.
+// Install it before the logging interceptor.
+func RequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestContext(ctx), req)
+	}
+}
+
+// RequestIDStreamInterceptor returns a new stream server interceptor that stores the request
+// id and trace in the stream context.
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestContext(ss.Context())})
+	}
+}
+
+// withRequestContext returns ctx carrying the request id and trace of the incoming metadata.
+func withRequestContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstValue(md, strings.ToLower(constants.RequestIDHeader))
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	ctx = logger.WithRequestID(ctx, requestID)
+
+	if traceparent := firstValue(md, traceparentKey); traceparent != "" {
+		ctx = logger.WithTraceparent(ctx, traceparent)
+	}
+	return ctx
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden context.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,10 @@
+# Configuration documentation rules
+
+##@ Documentation
+
+.PHONY: gen-config-docs
+gen-config-docs: ## Generate the configuration JSON Schema and Markdown reference
+	@echo "Generating configuration schema and reference..."
+	$(GO_CMD) run ./cmd/confdoc -format schema -o configs/config.schema.json
+	$(GO_CMD) run ./cmd/confdoc -format markdown -o docs/config.md
+	@echo "Configuration documentation generated successfully!"